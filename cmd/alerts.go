@@ -0,0 +1,199 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/spf13/cobra"
+)
+
+// Alert is a code scanning alert.
+// https://docs.github.com/en/rest/code-scanning/code-scanning?apiVersion=2022-11-28#get-a-code-scanning-alert
+type Alert struct {
+	Number      int    `json:"number"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+	URL         string `json:"url"`
+	HTMLURL     string `json:"html_url"`
+	State       string `json:"state"`
+	FixedAt     string `json:"fixed_at"`
+	DismissedBy *struct {
+		Login string `json:"login"`
+	} `json:"dismissed_by"`
+	DismissedAt      string `json:"dismissed_at"`
+	DismissedReason  string `json:"dismissed_reason"`
+	DismissedComment string `json:"dismissed_comment"`
+	Rule             struct {
+		ID                    string   `json:"id"`
+		Name                  string   `json:"name"`
+		Severity              string   `json:"severity"`
+		SecuritySeverityLevel string   `json:"security_severity_level"`
+		Description           string   `json:"description"`
+		FullDescription       string   `json:"full_description"`
+		Tags                  []string `json:"tags"`
+		Help                  string   `json:"help"`
+		HelpURI               string   `json:"help_uri"`
+	} `json:"rule"`
+	Tool struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Guid    string `json:"guid"`
+	} `json:"tool"`
+	MostRecentInstance AlertInstance `json:"most_recent_instance"`
+	InstancesURL       string        `json:"instances_url"`
+}
+
+// AlertInstance is an alert as found by one analysis, on one ref.
+type AlertInstance struct {
+	Ref         string `json:"ref"`
+	AnalysisKey string `json:"analysis_key"`
+	Environment string `json:"environment"`
+	Category    string `json:"category"`
+	State       string `json:"state"`
+	CommitSha   string `json:"commit_sha"`
+	Message     struct {
+		Text string `json:"text"`
+	} `json:"message"`
+	Location        AlertLocation `json:"location"`
+	Classifications []string      `json:"classifications"`
+}
+
+// AlertLocation is where in the code an alert instance is.
+type AlertLocation struct {
+	Path        string `json:"path"`
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+	StartColumn int    `json:"start_column"`
+	EndColumn   int    `json:"end_column"`
+}
+
+// String formats the location as path:startLine:startColumn.
+func (l AlertLocation) String() string {
+	s := l.Path
+	if l.StartLine > 0 {
+		s += fmt.Sprintf(":%d", l.StartLine)
+		if l.StartColumn > 0 {
+			s += fmt.Sprintf(":%d", l.StartColumn)
+		}
+	}
+	return s
+}
+
+// severity returns the severity GitHub shows for an alert: its security severity level
+// for security alerts, and the rule's severity otherwise.
+func (a Alert) severity() string {
+	if a.Rule.SecuritySeverityLevel != "" {
+		return a.Rule.SecuritySeverityLevel
+	}
+	return a.Rule.Severity
+}
+
+// alertSeverityColor returns the color to print a severity in.
+func alertSeverityColor(severity string) func(string) string {
+	switch severity {
+	case "critical", "high", "error":
+		return func(s string) string { return "\u001B[91m" + s + "\u001B[39m" }
+	case "medium", "warning":
+		return func(s string) string { return "\u001B[93m" + s + "\u001B[39m" }
+	default:
+		return func(s string) string { return "\u001B[96m" + s + "\u001B[39m" }
+	}
+}
+
+// The reasons an alert can be dismissed for, as the API spells them.
+var dismissReasons = []string{"false positive", "won't fix", "used in tests"}
+
+// parseDismissReason accepts a dismissal reason as the API spells it or as a flag value, such as wont-fix.
+func parseDismissReason(s string) (string, error) {
+	r := strings.ToLower(strings.NewReplacer("-", " ", "_", " ").Replace(s))
+	if r == "wont fix" {
+		r = "won't fix"
+	}
+	if !slices.Contains(dismissReasons, r) {
+		return "", fmt.Errorf("invalid dismissal reason %q: must be false-positive, wont-fix or used-in-tests", s)
+	}
+	return r, nil
+}
+
+// alertsPath returns the API path of the code scanning alerts of a repository.
+func alertsPath(repo repository.Repository) string {
+	return fmt.Sprintf("repos/%v/%v/code-scanning/alerts", repo.Owner, repo.Name)
+}
+
+// getAlert fetches an alert, returning it both parsed and as the API sent it.
+func getAlert(client *api.RESTClient, repo repository.Repository, number string) (Alert, json.RawMessage, error) {
+	var a Alert
+	response, err := client.Request(http.MethodGet, fmt.Sprintf("%v/%v", alertsPath(repo), number), nil)
+	if err != nil {
+		return a, nil, err
+	}
+	defer response.Body.Close()
+	b, err := io.ReadAll(response.Body)
+	if err != nil {
+		return a, nil, err
+	}
+	err = json.Unmarshal(b, &a)
+	return a, b, err
+}
+
+// alertUpdate is the body of a request to dismiss or reopen an alert.
+type alertUpdate struct {
+	State            string `json:"state"`
+	DismissedReason  string `json:"dismissed_reason,omitempty"`
+	DismissedComment string `json:"dismissed_comment,omitempty"`
+}
+
+// updateAlert changes the state of an alert, returning the updated alert as the API sent it.
+// Rate limited requests are retried.
+func updateAlert(client *api.RESTClient, repo repository.Repository, number int, update alertUpdate) (json.RawMessage, error) {
+	body, err := json.Marshal(update)
+	if err != nil {
+		return nil, err
+	}
+	response, err := requestWithRetry(client, http.MethodPatch, fmt.Sprintf("%v/%v", alertsPath(repo), number), body)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	return io.ReadAll(response.Body)
+}
+
+// alertsCmd represents the alerts command
+var alertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "List, view, dismiss and reopen GitHub Code Scanning alerts",
+	Long: `Triage the code scanning alerts of a repository.
+
+	The alert numbers shown by "gh sarif view" (the Alert Number column) can be used with these commands.`,
+}
+
+func init() {
+	rootCmd.AddCommand(alertsCmd)
+}
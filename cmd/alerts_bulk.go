@@ -0,0 +1,293 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/prompter"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+// The number of alerts updated at the same time.
+const alertsConcurrency = 4
+
+// alertChange records a change to the state of an alert, as written to --report.
+// A report can be passed to "alerts reopen --from-report" to reverse a bulk dismissal.
+type alertChange struct {
+	Number           int    `json:"number"`
+	Rule             string `json:"rule,omitempty"`
+	Location         string `json:"location,omitempty"`
+	PreviousState    string `json:"previous_state,omitempty"`
+	State            string `json:"state"`
+	DismissedReason  string `json:"dismissed_reason,omitempty"`
+	DismissedComment string `json:"dismissed_comment,omitempty"`
+	Match            string `json:"match,omitempty"`
+	Error            string `json:"error,omitempty"`
+
+	alert json.RawMessage
+}
+
+// alertSelector picks the open alerts to dismiss in bulk.
+type alertSelector struct {
+	rule       *regexp.Regexp
+	path       *regexp.Regexp
+	suppressed []resultRow
+}
+
+// newAlertSelector builds an alertSelector from the dismiss flags.
+func newAlertSelector() (alertSelector, error) {
+	var s alertSelector
+	var err error
+	if dismissRuleFlag != "" {
+		if s.rule, err = ruleGlobRegexp(dismissRuleFlag); err != nil {
+			return s, fmt.Errorf("invalid --rule: %w", err)
+		}
+	}
+	if dismissPathFlag != "" {
+		if s.path, err = globRegexp(dismissPathFlag); err != nil {
+			return s, fmt.Errorf("invalid --path: %w", err)
+		}
+	}
+	if dismissFromSarifFlag != "" {
+		b, err := readSarifInput(dismissFromSarifFlag)
+		if err != nil {
+			return s, err
+		}
+		r, err := sarif.FromBytes(b)
+		if err != nil {
+			return s, err
+		}
+		for _, row := range collectResults(r) {
+			if row.suppressed() {
+				s.suppressed = append(s.suppressed, row)
+			}
+		}
+		if len(s.suppressed) == 0 {
+			return s, fmt.Errorf("%v has no suppressed results", dismissFromSarifFlag)
+		}
+	}
+	return s, nil
+}
+
+// suppressed reports whether a result has an accepted suppression.
+func (row resultRow) suppressed() bool {
+	for _, s := range row.result.Suppressions {
+		if s != nil && (s.Status == nil || *s.Status == "" || *s.Status == "accepted") {
+			return true
+		}
+	}
+	return false
+}
+
+// justification returns the justification of the result's first suppression that has one.
+func (row resultRow) justification() string {
+	for _, s := range row.result.Suppressions {
+		if s != nil && s.Justification != nil {
+			return *s.Justification
+		}
+	}
+	return ""
+}
+
+// match reports whether an alert is selected, returning the suppressed result it matched if any.
+// Suppressed results match their alert by number, or else by rule, path and start line.
+func (s alertSelector) match(a Alert) (bool, *resultRow) {
+	if s.rule != nil && !s.rule.MatchString(a.Rule.ID) {
+		return false, nil
+	}
+	loc := a.MostRecentInstance.Location
+	if s.path != nil && !s.path.MatchString(loc.Path) {
+		return false, nil
+	}
+	if s.suppressed == nil {
+		return true, nil
+	}
+	for i, row := range s.suppressed {
+		if row.alertNumber() == strconv.Itoa(a.Number) {
+			return true, &s.suppressed[i]
+		}
+	}
+	for i, row := range s.suppressed {
+		if row.alertNumber() == "" && row.ruleID() == a.Rule.ID && row.file() == loc.Path && row.startLine() == strconv.Itoa(loc.StartLine) {
+			return true, &s.suppressed[i]
+		}
+	}
+	return false, nil
+}
+
+// selectOpenAlerts fetches every open alert, on --ref if given, and returns the changes that dismiss the selected ones.
+func selectOpenAlerts(client *api.RESTClient, repo repository.Repository, s alertSelector, update alertUpdate) ([]alertChange, error) {
+	params := url.Values{}
+	params.Add("state", "open")
+	params.Add("per_page", strconv.Itoa(maxPerPage))
+	if dismissRefFlag != "" {
+		params.Add("ref", dismissRefFlag)
+	}
+	u := url.URL{Path: alertsPath(repo), RawQuery: params.Encode()}
+
+	alerts, err := fetchAlerts(client, u.String())
+	if err != nil {
+		return nil, err
+	}
+	var changes []alertChange
+	for _, a := range alerts {
+		ok, row := s.match(a)
+		if !ok {
+			continue
+		}
+		c := alertChange{
+			Number:           a.Number,
+			Rule:             a.Rule.ID,
+			Location:         a.MostRecentInstance.Location.String(),
+			PreviousState:    a.State,
+			State:            update.State,
+			DismissedReason:  update.DismissedReason,
+			DismissedComment: update.DismissedComment,
+		}
+		// Without --comment, the justification of the suppression explains the dismissal.
+		if row != nil && c.DismissedComment == "" {
+			c.DismissedComment = truncateComment(row.justification())
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// fetchAlerts fetches every page of alerts from path.
+func fetchAlerts(client *api.RESTClient, path string) ([]Alert, error) {
+	var alerts []Alert
+	err := fetchPages(client, path, true, func(body []byte) (bool, error) {
+		var page []Alert
+		if err := json.Unmarshal(body, &page); err != nil {
+			return false, err
+		}
+		alerts = append(alerts, page...)
+		return len(page) > 0, nil
+	})
+	return alerts, err
+}
+
+// truncateComment shortens a comment to the longest the API accepts.
+func truncateComment(s string) string {
+	if r := []rune(s); len(r) > maxDismissCommentLength {
+		return string(r[:maxDismissCommentLength-3]) + "..."
+	}
+	return s
+}
+
+// previewAlertChanges prints the alerts about to be changed.
+func previewAlertChanges(changes []alertChange) error {
+	terminal := term.FromEnv()
+	termWidth, _, _ := terminal.Size()
+	t := tableprinter.New(terminal.Out(), terminal.IsTerminalOutput(), termWidth)
+	// Dismissals copied from other alerts show their reason and how they were matched.
+	matched := slices.ContainsFunc(changes, func(c alertChange) bool { return c.Match != "" })
+	header := []string{"Number", "Rule", "Location", "Comment"}
+	if matched {
+		header = append(header, "Reason", "Match")
+	}
+	t.AddHeader(header)
+	for _, c := range changes {
+		t.AddField(strconv.Itoa(c.Number), tableprinter.WithTruncate(nil))
+		t.AddField(c.Rule)
+		t.AddField(c.Location)
+		t.AddField(c.DismissedComment)
+		if matched {
+			t.AddField(c.DismissedReason)
+			t.AddField(c.Match)
+		}
+		t.EndRow()
+	}
+	return t.Render()
+}
+
+// confirmAlertChanges asks whether to go ahead, unless --yes was given.
+func confirmAlertChanges(prompt string) (bool, error) {
+	if yesFlag {
+		return true, nil
+	}
+	terminal := term.FromEnv()
+	if !terminal.IsTerminalOutput() || !term.IsTerminal(os.Stdin) {
+		return false, errors.New("use --yes to confirm when not running interactively")
+	}
+	return prompter.New(os.Stdin, os.Stdout, os.Stderr).Confirm(prompt, false)
+}
+
+// applyAlertChanges updates alerts concurrently, recording the outcome of each change.
+func applyAlertChanges(client *api.RESTClient, repo repository.Repository, changes []alertChange) {
+	sem := make(chan struct{}, alertsConcurrency)
+	var wg sync.WaitGroup
+	for i := range changes {
+		wg.Add(1)
+		go func(c *alertChange) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			update := alertUpdate{State: c.State, DismissedReason: c.DismissedReason, DismissedComment: c.DismissedComment}
+			raw, err := updateAlert(client, repo, c.Number, update)
+			if err != nil {
+				c.Error = err.Error()
+				return
+			}
+			c.alert = raw
+		}(&changes[i])
+	}
+	wg.Wait()
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Number < changes[j].Number })
+}
+
+// writeAlertReport writes the changes made to a JSON file.
+func writeAlertReport(path string, changes []alertChange) error {
+	b, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(path, append(b, '\n'))
+}
+
+// readAlertReport reads a report written by --report.
+func readAlertReport(path string) ([]alertChange, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var changes []alertChange
+	if err := json.Unmarshal(b, &changes); err != nil {
+		return nil, fmt.Errorf("%v is not a report written by --report: %w", path, err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAlertSelector(t *testing.T) {
+	alerts := []Alert{
+		testAlert(1, "go/sql-injection", "café/db.go", 3, "open"),
+		testAlert(2, "go/sql-injection", "cafe/db.go", 3, "open"),
+		testAlert(3, "go/xss", "café/web/handler.go", 7, "open"),
+		testAlert(4, "js/xss", "vendor/日本/lib.js", 1, "open"),
+	}
+	tests := []struct {
+		name string
+		rule string
+		path string
+		want []int
+	}{
+		{name: "non-ASCII path", path: "café/*.go", want: []int{1}},
+		{name: "non-ASCII path with double star", path: "café/**", want: []int{1, 3}},
+		{name: "non-ASCII directory under vendor", path: "vendor/日本/*", want: []int{4}},
+		{name: "rule star matches across slashes", rule: "*injection", want: []int{1, 2}},
+		{name: "rule and path together", rule: "*/xss", path: "**/*.go", want: []int{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dismissRuleFlag, dismissPathFlag = tt.rule, tt.path
+			t.Cleanup(func() { dismissRuleFlag, dismissPathFlag = "", "" })
+
+			s, err := newAlertSelector()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, a := range alerts {
+				if ok, _ := s.match(a); ok {
+					got = append(got, a.Number)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selected alerts %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
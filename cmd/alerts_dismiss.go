@@ -0,0 +1,301 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// The longest dismissal comment the API accepts.
+const maxDismissCommentLength = 280
+
+var dismissReasonFlag string
+var dismissCommentFlag string
+
+// Bulk dismissal flags
+var dismissRuleFlag string
+var dismissPathFlag string
+var dismissFromSarifFlag string
+var dismissRefFlag string
+var reopenFromReportFlag string
+var alertsReportFlag string
+var dryRunFlag bool
+var yesFlag bool
+
+// alertsDismissCmd represents the alerts dismiss command
+var alertsDismissCmd = &cobra.Command{
+	Use:   "dismiss {<alert-number>... | --rule <glob> | --path <glob> | --from-sarif <file>} --reason <reason>",
+	Short: "Dismiss code scanning alerts",
+	Long: `Dismiss one or more code scanning alerts.
+
+	--reason is one of false-positive, wont-fix or used-in-tests. --comment explains the dismissal (at most 280 characters).
+
+	Instead of alert numbers, --rule and --path select every open alert whose rule ID and path match a glob.
+	In --rule, * matches any characters including "/"; in --path, ** matches any number of directories. --from-sarif selects the open alerts of the suppressed results
+	in a SARIF file, matched by alert number or else by rule, path and line. Their suppression's justification
+	is the comment unless --comment is given. The selected alerts are listed for confirmation before they're dismissed.
+
+	--report writes what was changed to a JSON file, which "gh sarif alerts reopen --from-report" can reverse.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		reason, err := parseDismissReason(dismissReasonFlag)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if len([]rune(dismissCommentFlag)) > maxDismissCommentLength {
+			fmt.Printf("--comment is longer than %v characters.\n", maxDismissCommentLength)
+			return
+		}
+		bulk := dismissRuleFlag != "" || dismissPathFlag != "" || dismissFromSarifFlag != ""
+		if bulk == (len(args) > 0) {
+			fmt.Println("Give either alert numbers, or --rule, --path or --from-sarif to select alerts.")
+			return
+		}
+
+		update := alertUpdate{State: "dismissed", DismissedReason: reason, DismissedComment: dismissCommentFlag}
+		if !bulk {
+			setAlertsState(args, update)
+			return
+		}
+
+		// Setup Repository
+		repo, err := GetRepository()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := checkJSONFields(jsonFields(Alert{})); err != nil {
+			fmt.Println(err)
+			return
+		}
+		selector, err := newAlertSelector()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		client, err := newRESTClient(repo, nil)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		changes, err := selectOpenAlerts(client, repo, selector, update)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if len(changes) == 0 {
+			fmt.Println("No open alerts match.")
+			return
+		}
+		if !confirmBulkChanges(changes, fmt.Sprintf("Dismiss %v alerts as %v?", len(changes), reason)) {
+			return
+		}
+		applyAlertChanges(client, repo, changes)
+		reportAlertChanges(changes)
+	},
+}
+
+// alertsReopenCmd represents the alerts reopen command
+var alertsReopenCmd = &cobra.Command{
+	Use:   "reopen {<alert-number>... | --from-report <file>}",
+	Short: "Reopen dismissed code scanning alerts",
+	Long: `Reopen one or more dismissed code scanning alerts.
+
+	--from-report reopens the alerts dismissed by a "gh sarif alerts dismiss --report" run, reversing it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if (reopenFromReportFlag != "") == (len(args) > 0) {
+			fmt.Println("Give either alert numbers or --from-report.")
+			return
+		}
+		update := alertUpdate{State: "open"}
+		if reopenFromReportFlag == "" {
+			setAlertsState(args, update)
+			return
+		}
+
+		report, err := readAlertReport(reopenFromReportFlag)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		// Only reopen alerts that the reported run dismissed.
+		var changes []alertChange
+		for _, c := range report {
+			if c.Error == "" && c.State == "dismissed" && c.PreviousState == "open" {
+				changes = append(changes, alertChange{Number: c.Number, Rule: c.Rule, Location: c.Location, PreviousState: c.State, State: update.State})
+			}
+		}
+		if len(changes) == 0 {
+			fmt.Printf("%v has no dismissed alerts to reopen.\n", reopenFromReportFlag)
+			return
+		}
+
+		// Setup Repository
+		repo, err := GetRepository()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := checkJSONFields(jsonFields(Alert{})); err != nil {
+			fmt.Println(err)
+			return
+		}
+		client, err := newRESTClient(repo, nil)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !confirmBulkChanges(changes, fmt.Sprintf("Reopen %v alerts?", len(changes))) {
+			return
+		}
+		applyAlertChanges(client, repo, changes)
+		reportAlertChanges(changes)
+	},
+}
+
+// confirmBulkChanges previews the alerts about to be changed and asks for confirmation.
+// It returns false if the changes shouldn't be made, including for --dry-run.
+func confirmBulkChanges(changes []alertChange, prompt string) bool {
+	if !jsonOutput() || dryRunFlag {
+		if err := previewAlertChanges(changes); err != nil {
+			fmt.Println(err)
+			return false
+		}
+	}
+	if dryRunFlag {
+		return false
+	}
+	ok, err := confirmAlertChanges(prompt)
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	return ok
+}
+
+// setAlertsState applies an update to every alert given as an argument.
+func setAlertsState(args []string, update alertUpdate) {
+	// Setup Repository
+	repo, err := GetRepository()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if err := checkJSONFields(jsonFields(Alert{})); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var changes []alertChange
+	for _, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			fmt.Printf("invalid alert number %q\n", arg)
+			return
+		}
+		changes = append(changes, alertChange{Number: n, State: update.State, DismissedReason: update.DismissedReason, DismissedComment: update.DismissedComment})
+	}
+
+	if dryRunFlag {
+		if err := previewAlertChanges(changes); err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+
+	client, err := newRESTClient(repo, nil)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	applyAlertChanges(client, repo, changes)
+	reportAlertChanges(changes)
+}
+
+// reportAlertChanges prints what happened to each alert, and writes the --report file.
+// It exits with status 1 if any alert couldn't be updated.
+func reportAlertChanges(changes []alertChange) {
+	updated := []json.RawMessage{}
+	failed := 0
+	for _, c := range changes {
+		if c.Error != "" {
+			fmt.Fprintf(os.Stderr, "Failed to update alert #%v: %v\n", c.Number, c.Error)
+			failed++
+			continue
+		}
+		updated = append(updated, c.alert)
+		if jsonOutput() {
+			continue
+		}
+		if c.State == "dismissed" {
+			fmt.Printf("Dismissed alert #%v as %v\n", c.Number, c.DismissedReason)
+		} else {
+			fmt.Printf("Reopened alert #%v\n", c.Number)
+		}
+	}
+
+	if jsonOutput() {
+		b, _ := json.Marshal(updated)
+		if err := printJSON(b); err != nil {
+			fmt.Println(err)
+		}
+	} else if len(changes) > 1 {
+		fmt.Printf("Updated %v of %v alerts.\n", len(updated), len(changes))
+	}
+	if alertsReportFlag != "" {
+		if err := writeAlertReport(alertsReportFlag, changes); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	alertsCmd.AddCommand(alertsDismissCmd)
+	alertsCmd.AddCommand(alertsReopenCmd)
+	setJSONFields(alertsDismissCmd, Alert{})
+	setJSONFields(alertsReopenCmd, Alert{})
+	alertsDismissCmd.Flags().StringVarP(&dismissReasonFlag, "reason", "r", "", "Why the alerts are dismissed: false-positive, wont-fix or used-in-tests")
+	alertsDismissCmd.Flags().StringVarP(&dismissCommentFlag, "comment", "c", "", "A comment on the dismissal")
+	alertsDismissCmd.MarkFlagRequired("reason")
+	alertsDismissCmd.Flags().StringVar(&dismissRuleFlag, "rule", "", "Dismiss open alerts of rules whose ID matches a glob (* matches any characters, including /)")
+	alertsDismissCmd.Flags().StringVar(&dismissPathFlag, "path", "", "Dismiss open alerts with a path matching a glob (** matches any directories)")
+	alertsDismissCmd.Flags().StringVar(&dismissFromSarifFlag, "from-sarif", "", "Dismiss open alerts of the suppressed results in a SARIF file")
+	alertsDismissCmd.Flags().StringVar(&dismissRefFlag, "ref", "", "Select alerts on this ref instead of the default branch")
+
+	for _, c := range []*cobra.Command{alertsDismissCmd, alertsReopenCmd} {
+		c.Flags().StringVar(&alertsReportFlag, "report", "", "Write a JSON report of the changes to a file (- for stdout)")
+		c.Flags().BoolVar(&dryRunFlag, "dry-run", false, "List the alerts that would be changed without changing them")
+		c.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Don't ask for confirmation")
+	}
+	alertsReopenCmd.Flags().StringVar(&reopenFromReportFlag, "from-report", "", "Reopen the alerts dismissed in a report written by \"alerts dismiss --report\"")
+}
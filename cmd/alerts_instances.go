@@ -0,0 +1,157 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/spf13/cobra"
+)
+
+var instancesRefFlag string
+
+// alertsInstancesCmd represents the alerts instances command
+var alertsInstancesCmd = &cobra.Command{
+	Use:   "instances <alert-number>",
+	Short: "List where a code scanning alert occurs across refs",
+	Long: `List the instances of a code scanning alert: each ref and analysis it was found by, with its state there.
+
+	This shows, for example, whether an alert that is fixed on the default branch is still open on release branches.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Setup Repository
+		repo, err := GetRepository()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if err := checkJSONFields(jsonFields(AlertInstance{})); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if _, err := strconv.Atoi(args[0]); err != nil {
+			fmt.Printf("invalid alert number %q\n", args[0])
+			return
+		}
+
+		params := url.Values{}
+		params.Add("per_page", strconv.Itoa(maxPerPage))
+		if instancesRefFlag != "" {
+			params.Add("ref", instancesRefFlag)
+		}
+		u := url.URL{Path: fmt.Sprintf("%v/%v/instances", alertsPath(repo), args[0]), RawQuery: params.Encode()}
+
+		client, err := newRESTClient(repo, nil)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		rawInstances := []json.RawMessage{}
+		var instances []AlertInstance
+		err = fetchPages(client, u.String(), true, func(body []byte) (bool, error) {
+			var page []json.RawMessage
+			if err := json.Unmarshal(body, &page); err != nil {
+				return false, err
+			}
+			for _, raw := range page {
+				var instance AlertInstance
+				if err := json.Unmarshal(raw, &instance); err != nil {
+					return false, err
+				}
+				rawInstances = append(rawInstances, raw)
+				instances = append(instances, instance)
+			}
+			return len(page) > 0, nil
+		})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if jsonOutput() {
+			b, err := json.Marshal(rawInstances)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if err := printJSON(b); err != nil {
+				fmt.Println(err)
+			}
+			return
+		}
+
+		cyan := func(s string) string {
+			return "\u001B[96m" + s + "\u001B[39m"
+		}
+
+		red := func(s string) string {
+			return "\u001B[91m" + s + "\u001B[39m"
+		}
+
+		yellow := func(s string) string {
+			return "\u001B[93m" + s + "\u001B[39m"
+		}
+
+		// Table Print
+		terminal := term.FromEnv()
+		termWidth, _, _ := terminal.Size()
+		t := tableprinter.New(terminal.Out(), terminal.IsTerminalOutput(), termWidth)
+
+		if terminal.IsTerminalOutput() {
+			fmt.Printf("Showing %d instances of alert #%v\n\n", len(instances), args[0])
+		}
+
+		t.AddHeader([]string{"Ref", "Analysis Key", "Category", "State", "Commit", "Location"})
+		for _, instance := range instances {
+			state := cyan
+			switch instance.State {
+			case "open":
+				state = red
+			case "dismissed":
+				state = yellow
+			}
+			t.AddField(instance.Ref)
+			t.AddField(instance.AnalysisKey)
+			t.AddField(instance.Category)
+			t.AddField(instance.State, tableprinter.WithColor(state))
+			t.AddField(instance.CommitSha, tableprinter.WithTruncate(nil))
+			t.AddField(instance.Location.String())
+			t.EndRow()
+		}
+		if err := t.Render(); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+func init() {
+	alertsCmd.AddCommand(alertsInstancesCmd)
+	setJSONFields(alertsInstancesCmd, AlertInstance{})
+	alertsInstancesCmd.Flags().StringVarP(&instancesRefFlag, "ref", "r", "", "Only list instances on this ref, either refs/heads/<branch name> or <branch name>. To reference a pull request use refs/pull/<number>/merge.")
+}
@@ -0,0 +1,204 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/spf13/cobra"
+)
+
+// Supported params:
+// https://docs.github.com/en/rest/code-scanning/code-scanning?apiVersion=2022-11-28#list-code-scanning-alerts-for-a-repository
+var alertsStateFlag string
+var alertsSeverityFlag string
+var alertsToolFlag string
+var alertsRefFlag string
+var alertsSortFlag string
+var alertsDirectionFlag string
+var alertsPageFlag int
+var alertsLimitFlag int
+var alertsPaginateFlag bool
+
+var alertStates = []string{"open", "closed", "dismissed", "fixed"}
+var alertSeverities = []string{"critical", "high", "medium", "low", "warning", "note", "error"}
+
+// alertsListCmd represents the alerts list command
+var alertsListCmd = &cobra.Command{
+	Use:   "list [flags]",
+	Short: "List code scanning alerts for a repository",
+	Long: fmt.Sprintf(`List code scanning alerts for a repository. By default, the most recent %v alerts are listed.
+
+	Use --paginate to fetch every page of alerts. --limit then caps the total number of alerts listed.`, defaultLimit),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Setup Repository
+		repo, err := GetRepository()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if err := checkJSONFields(jsonFields(Alert{})); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if alertsStateFlag != "" && !slices.Contains(alertStates, alertsStateFlag) {
+			fmt.Printf("invalid --state %q: must be one of %v\n", alertsStateFlag, alertStates)
+			return
+		}
+		if alertsSeverityFlag != "" && !slices.Contains(alertSeverities, alertsSeverityFlag) {
+			fmt.Printf("invalid --severity %q: must be one of %v\n", alertsSeverityFlag, alertSeverities)
+			return
+		}
+
+		// When paginating, --limit caps the total number of alerts rather than the page size.
+		limit := alertsLimitFlag
+		perPage := alertsLimitFlag
+		if alertsPaginateFlag {
+			perPage = maxPerPage
+			if !cmd.Flags().Changed("limit") {
+				limit = 0
+			}
+		}
+
+		params := url.Values{}
+		params.Add("per_page", fmt.Sprintf("%v", perPage))
+		if alertsStateFlag != "" {
+			params.Add("state", alertsStateFlag)
+		}
+		if alertsSeverityFlag != "" {
+			params.Add("severity", alertsSeverityFlag)
+		}
+		if alertsToolFlag != "" {
+			params.Add("tool_name", alertsToolFlag)
+		}
+		if alertsRefFlag != "" {
+			params.Add("ref", alertsRefFlag)
+		}
+		if alertsSortFlag != "" {
+			params.Add("sort", alertsSortFlag)
+		}
+		if alertsDirectionFlag != "" {
+			params.Add("direction", alertsDirectionFlag)
+		}
+		if alertsPageFlag != 1 {
+			params.Add("page", fmt.Sprintf("%v", alertsPageFlag))
+		}
+		u := url.URL{Path: alertsPath(repo), RawQuery: params.Encode()}
+
+		client, err := newRESTClient(repo, nil)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		var rawAlerts []json.RawMessage
+		var alerts []Alert
+		err = fetchPages(client, u.String(), alertsPaginateFlag, func(body []byte) (bool, error) {
+			var page []json.RawMessage
+			if err := json.Unmarshal(body, &page); err != nil {
+				return false, err
+			}
+			for _, raw := range page {
+				var a Alert
+				if err := json.Unmarshal(raw, &a); err != nil {
+					return false, err
+				}
+				rawAlerts = append(rawAlerts, raw)
+				alerts = append(alerts, a)
+				if limit > 0 && len(alerts) >= limit {
+					return false, nil
+				}
+			}
+			return len(page) > 0, nil
+		})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if jsonOutput() {
+			if rawAlerts == nil {
+				rawAlerts = []json.RawMessage{}
+			}
+			b, err := json.Marshal(rawAlerts)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if err := printJSON(b); err != nil {
+				fmt.Println(err)
+			}
+			return
+		}
+
+		// Table Print
+		terminal := term.FromEnv()
+		termWidth, _, _ := terminal.Size()
+		t := tableprinter.New(terminal.Out(), terminal.IsTerminalOutput(), termWidth)
+
+		if terminal.IsTerminalOutput() && alertsPaginateFlag {
+			fmt.Printf("Showing %d alerts\n\n", len(alerts))
+		} else if terminal.IsTerminalOutput() {
+			fmt.Printf("Showing %d alerts on page %d/?\n\n", len(alerts), alertsPageFlag)
+		}
+
+		t.AddHeader([]string{"Number", "State", "Severity", "Rule", "Tool", "Location", "Ref", "Created At"})
+		for _, a := range alerts {
+			severity := a.severity()
+			t.AddField(strconv.Itoa(a.Number), tableprinter.WithTruncate(nil))
+			t.AddField(a.State)
+			t.AddField(severity, tableprinter.WithColor(alertSeverityColor(severity)))
+			t.AddField(a.Rule.ID)
+			t.AddField(a.Tool.Name)
+			t.AddField(a.MostRecentInstance.Location.String())
+			t.AddField(a.MostRecentInstance.Ref)
+			t.AddField(a.CreatedAt)
+			t.EndRow()
+		}
+		if err := t.Render(); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+func init() {
+	alertsCmd.AddCommand(alertsListCmd)
+	setJSONFields(alertsListCmd, Alert{})
+	alertsListCmd.Flags().StringVarP(&alertsStateFlag, "state", "s", "", "Only list alerts in this state: open, closed, dismissed or fixed")
+	alertsListCmd.Flags().StringVar(&alertsSeverityFlag, "severity", "", "Only list alerts of this severity: critical, high, medium, low, warning, note or error")
+	alertsListCmd.Flags().StringVarP(&alertsToolFlag, "tool", "t", "", "Only list alerts of this tool")
+	alertsListCmd.Flags().StringVarP(&alertsRefFlag, "ref", "r", "", "Only list alerts on this ref, either refs/heads/<branch name> or <branch name>. To reference a pull request use refs/pull/<number>/merge.")
+	alertsListCmd.Flags().StringVar(&alertsSortFlag, "sort", "", "The property to sort alerts by: created or updated")
+	alertsListCmd.Flags().StringVar(&alertsDirectionFlag, "direction", "", "The direction to sort alerts in: asc or desc")
+	alertsListCmd.Flags().IntVarP(&alertsPageFlag, "page", "p", 1, "Page number of alerts to return")
+	alertsListCmd.Flags().IntVarP(&alertsLimitFlag, "limit", "L", defaultLimit, "Number of alerts to list: the page size (max 100), or the total with --paginate")
+	alertsListCmd.Flags().BoolVar(&alertsPaginateFlag, "paginate", false, "Fetch all pages of alerts (--limit becomes the total number of alerts)")
+	alertsListCmd.Flags().BoolVar(&alertsPaginateFlag, "all", false, "Alias for --paginate")
+}
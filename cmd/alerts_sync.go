@@ -0,0 +1,329 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/owenrumney/go-sarif/v2/sarif"
+	"github.com/spf13/cobra"
+)
+
+var syncFromFlag string
+var syncToFlag string
+var syncFromRefFlag string
+var syncToRefFlag string
+
+// How a dismissed alert was matched to an open alert.
+const (
+	matchFingerprint = "fingerprint"
+	matchLocation    = "location"
+)
+
+// alertSide is the repository and ref that dismissals are copied from or to.
+type alertSide struct {
+	repo   repository.Repository
+	ref    string
+	client *api.RESTClient
+	// fingerprints maps alert numbers to the rule ID and primaryLocationLineHash of their result.
+	fingerprints map[int]string
+}
+
+// newAlertSide parses a repository flag, using the current repository if it's empty.
+func newAlertSide(repoFlag, ref string) (*alertSide, error) {
+	var repo repository.Repository
+	var err error
+	if repoFlag != "" {
+		repo, err = repository.Parse(repoFlag)
+	} else {
+		repo, err = GetRepository()
+	}
+	if err != nil {
+		return nil, err
+	}
+	client, err := newRESTClient(repo, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &alertSide{repo: repo, ref: ref, client: client}, nil
+}
+
+// String names the side as owner/repo, followed by the ref if there is one.
+func (s *alertSide) String() string {
+	name := fmt.Sprintf("%v/%v", s.repo.Owner, s.repo.Name)
+	if s.ref != "" {
+		name += "@" + s.ref
+	}
+	return name
+}
+
+// alerts fetches every alert in a state on the side's ref.
+func (s *alertSide) alerts(state string) ([]Alert, error) {
+	params := url.Values{}
+	params.Add("state", state)
+	params.Add("per_page", strconv.Itoa(maxPerPage))
+	if s.ref != "" {
+		params.Add("ref", s.ref)
+	}
+	u := url.URL{Path: alertsPath(s.repo), RawQuery: params.Encode()}
+	return fetchAlerts(s.client, u.String())
+}
+
+// loadFingerprints reads the fingerprints of alerts from the SARIF of the newest analysis of each
+// analysis set on the side's ref. Alerts are only given by number in the SARIF GitHub returns.
+// Sets whose SARIF can't be read are skipped with a warning, leaving their alerts to be matched by location.
+func (s *alertSide) loadFingerprints() error {
+	s.fingerprints = map[int]string{}
+	ref := s.ref
+	if ref == "" {
+		var r struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := s.client.Get(fmt.Sprintf("repos/%v/%v", s.repo.Owner, s.repo.Name), &r); err != nil {
+			return err
+		}
+		ref = "refs/heads/" + r.DefaultBranch
+	}
+
+	params := url.Values{}
+	params.Add("ref", ref)
+	params.Add("per_page", strconv.Itoa(maxPerPage))
+	u := url.URL{Path: fmt.Sprintf("repos/%v/%v/code-scanning/analyses", s.repo.Owner, s.repo.Name), RawQuery: params.Encode()}
+	var analyses []Analysis
+	err := fetchPages(s.client, u.String(), true, func(body []byte) (bool, error) {
+		var page []Analysis
+		if err := json.Unmarshal(body, &page); err != nil {
+			return false, err
+		}
+		analyses = append(analyses, page...)
+		return len(page) > 0, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sarifClient, err := newRESTClient(s.repo, map[string]string{"Accept": "application/sarif+json"})
+	if err != nil {
+		return err
+	}
+	for _, set := range groupAnalysisSets(analyses) {
+		var r *sarif.Report
+		b, err := downloadSarif(sarifClient, fmt.Sprintf("%v/%v", u.Path, set.Newest.ID))
+		if err == nil {
+			r, err = sarif.FromBytes(b)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping the fingerprints of analysis %v in %v (%v, category %q), its alerts can only be matched by location: %v\n",
+				set.Newest.ID, s, set.Tool, set.Category, err)
+			continue
+		}
+		for _, row := range collectResults(r) {
+			n, err := strconv.Atoi(row.alertNumber())
+			if err != nil {
+				continue
+			}
+			if fp, ok := row.result.PartialFingerprints["primaryLocationLineHash"]; ok {
+				s.fingerprints[n] = fmt.Sprintf("%v\x00%v", row.ruleID(), fp)
+			}
+		}
+	}
+	return nil
+}
+
+// locationKey identifies an alert by its rule and the location of its most recent instance.
+func locationKey(a Alert) string {
+	l := a.MostRecentInstance.Location
+	return fmt.Sprintf("%v\x00%v\x00%v\x00%v", a.Rule.ID, l.Path, l.StartLine, l.StartColumn)
+}
+
+// matchDismissals pairs each open alert with a dismissed alert of the same rule, by fingerprint
+// where both have one, and otherwise by location. Alerts whose fingerprints differ aren't matched
+// by location, as their code has changed. Each dismissed alert is used at most once.
+// It returns the dismissals to apply and the dismissed alerts that matched nothing.
+func matchDismissals(dismissed []Alert, open []Alert, from, to map[int]string) ([]alertChange, []Alert) {
+	byFingerprint := map[string][]int{}
+	byLocation := map[string][]int{}
+	for i, a := range dismissed {
+		if fp, ok := from[a.Number]; ok {
+			byFingerprint[fp] = append(byFingerprint[fp], i)
+		}
+		byLocation[locationKey(a)] = append(byLocation[locationKey(a)], i)
+	}
+
+	used := make([]bool, len(dismissed))
+	take := func(candidates []int, ok func(int) bool) (int, bool) {
+		for _, i := range candidates {
+			if !used[i] && ok(i) {
+				used[i] = true
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	var changes []alertChange
+	for _, a := range open {
+		match := matchFingerprint
+		fp, hasFP := to[a.Number]
+		i, ok := take(byFingerprint[fp], func(int) bool { return hasFP })
+		if !ok {
+			match = matchLocation
+			i, ok = take(byLocation[locationKey(a)], func(i int) bool {
+				_, dismissedFP := from[dismissed[i].Number]
+				return !hasFP || !dismissedFP
+			})
+		}
+		if !ok {
+			continue
+		}
+		d := dismissed[i]
+		changes = append(changes, alertChange{
+			Number:           a.Number,
+			Rule:             a.Rule.ID,
+			Location:         a.MostRecentInstance.Location.String(),
+			PreviousState:    a.State,
+			State:            "dismissed",
+			DismissedReason:  d.DismissedReason,
+			DismissedComment: d.DismissedComment,
+			Match:            fmt.Sprintf("%v (#%v)", match, d.Number),
+		})
+	}
+
+	var unmatched []Alert
+	for i, a := range dismissed {
+		if !used[i] {
+			unmatched = append(unmatched, a)
+		}
+	}
+	return changes, unmatched
+}
+
+// printMatchReport summarises how well dismissals matched between the two sides.
+func printMatchReport(w io.Writer, from, to *alertSide, dismissed int, changes []alertChange, unmatched []Alert) {
+	counts := map[string]int{}
+	for _, c := range changes {
+		for _, m := range []string{matchFingerprint, matchLocation} {
+			if strings.HasPrefix(c.Match, m) {
+				counts[m]++
+			}
+		}
+	}
+	fmt.Fprintf(w, "Matched %v of %v dismissed alerts in %v to open alerts in %v: %v by fingerprint, %v by location.\n",
+		len(changes), dismissed, from, to, counts[matchFingerprint], counts[matchLocation])
+	if len(unmatched) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%v dismissed alerts have no open match:\n", len(unmatched))
+	for _, a := range unmatched {
+		fmt.Fprintf(w, "  #%v %v %v\n", a.Number, a.Rule.ID, a.MostRecentInstance.Location)
+	}
+}
+
+// alertsSyncCmd represents the alerts sync-dismissals command
+var alertsSyncCmd = &cobra.Command{
+	Use:   "sync-dismissals --from <repo> --to <repo>",
+	Short: "Copy alert dismissals between repositories or branches",
+	Long: `Dismiss the open alerts of one repository or branch that are dismissed in another, with the same reason and comment.
+
+	Alerts are matched by rule ID and the primaryLocationLineHash fingerprint of their results, read from the SARIF
+	of the latest analyses on each side. Alerts without a fingerprint are matched by rule ID and location.
+	--from and --to default to the current repository, and --from-ref and --to-ref to the default branch.
+
+	The matches and a report of how they matched are printed for confirmation before any alerts are dismissed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if syncFromFlag == syncToFlag && syncFromRefFlag == syncToRefFlag {
+			fmt.Println("--from and --to must differ in repository or ref.")
+			return
+		}
+		if err := checkJSONFields(jsonFields(Alert{})); err != nil {
+			fmt.Println(err)
+			return
+		}
+		from, err := newAlertSide(syncFromFlag, syncFromRefFlag)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		to, err := newAlertSide(syncToFlag, syncToRefFlag)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		dismissed, err := from.alerts("dismissed")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if len(dismissed) == 0 {
+			fmt.Printf("%v has no dismissed alerts.\n", from)
+			return
+		}
+		open, err := to.alerts("open")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, side := range []*alertSide{from, to} {
+			if err := side.loadFingerprints(); err != nil {
+				fmt.Fprintf(os.Stderr, "Couldn't read fingerprints from %v, matching by location only: %v\n", side, err)
+			}
+		}
+
+		changes, unmatched := matchDismissals(dismissed, open, from.fingerprints, to.fingerprints)
+		if !jsonOutput() {
+			printMatchReport(os.Stdout, from, to, len(dismissed), changes, unmatched)
+		}
+		if len(changes) == 0 {
+			return
+		}
+		if !jsonOutput() {
+			fmt.Println()
+		}
+		if !confirmBulkChanges(changes, fmt.Sprintf("Dismiss %v alerts in %v?", len(changes), to)) {
+			return
+		}
+		applyAlertChanges(to.client, to.repo, changes)
+		reportAlertChanges(changes)
+	},
+}
+
+func init() {
+	alertsCmd.AddCommand(alertsSyncCmd)
+	setJSONFields(alertsSyncCmd, Alert{})
+	alertsSyncCmd.Flags().StringVar(&syncFromFlag, "from", "", "Repository to copy dismissals from (format: [HOST/]OWNER/REPO)")
+	alertsSyncCmd.Flags().StringVar(&syncToFlag, "to", "", "Repository to copy dismissals to (format: [HOST/]OWNER/REPO)")
+	alertsSyncCmd.Flags().StringVar(&syncFromRefFlag, "from-ref", "", "Ref to copy dismissals from, instead of the default branch")
+	alertsSyncCmd.Flags().StringVar(&syncToRefFlag, "to-ref", "", "Ref to copy dismissals to, instead of the default branch")
+	alertsSyncCmd.Flags().StringVar(&alertsReportFlag, "report", "", "Write a JSON report of the changes to a file (- for stdout)")
+	alertsSyncCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "List the matches without dismissing any alerts")
+	alertsSyncCmd.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Don't ask for confirmation")
+}
@@ -0,0 +1,151 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func testAlert(number int, rule, path string, line int, state string) Alert {
+	a := Alert{Number: number, State: state}
+	a.Rule.ID = rule
+	a.MostRecentInstance.Location = AlertLocation{Path: path, StartLine: line, StartColumn: 1}
+	if state == "dismissed" {
+		a.DismissedReason = "false positive"
+		a.DismissedComment = fmt.Sprintf("comment %v", number)
+	}
+	return a
+}
+
+func TestMatchDismissals(t *testing.T) {
+	tests := []struct {
+		name          string
+		dismissed     []Alert
+		open          []Alert
+		from, to      map[int]string
+		wantChanges   []string
+		wantUnmatched []int
+	}{
+		{
+			name:        "fingerprint match across moved code",
+			dismissed:   []Alert{testAlert(1, "go/sql-injection", "a.go", 10, "dismissed")},
+			open:        []Alert{testAlert(11, "go/sql-injection", "b/a.go", 42, "open")},
+			from:        map[int]string{1: "go/sql-injection\x00h1"},
+			to:          map[int]string{11: "go/sql-injection\x00h1"},
+			wantChanges: []string{"11 <- fingerprint (#1)"},
+		},
+		{
+			name:        "location fallback when the dismissed alert has no fingerprint",
+			dismissed:   []Alert{testAlert(2, "go/path-injection", "a.go", 3, "dismissed")},
+			open:        []Alert{testAlert(12, "go/path-injection", "a.go", 3, "open")},
+			from:        map[int]string{},
+			to:          map[int]string{12: "go/path-injection\x00h2"},
+			wantChanges: []string{"12 <- location (#2)"},
+		},
+		{
+			name:        "location fallback when the open alert has no fingerprint",
+			dismissed:   []Alert{testAlert(2, "go/path-injection", "a.go", 3, "dismissed")},
+			open:        []Alert{testAlert(12, "go/path-injection", "a.go", 3, "open")},
+			from:        map[int]string{2: "go/path-injection\x00h2"},
+			to:          map[int]string{},
+			wantChanges: []string{"12 <- location (#2)"},
+		},
+		{
+			name:          "differing fingerprints don't match by location",
+			dismissed:     []Alert{testAlert(3, "go/xss", "a.go", 7, "dismissed")},
+			open:          []Alert{testAlert(13, "go/xss", "a.go", 7, "open")},
+			from:          map[int]string{3: "go/xss\x00h3"},
+			to:            map[int]string{13: "go/xss\x00h4"},
+			wantUnmatched: []int{3},
+		},
+		{
+			name:          "different rules at the same location don't match",
+			dismissed:     []Alert{testAlert(4, "go/xss", "a.go", 7, "dismissed")},
+			open:          []Alert{testAlert(14, "go/sql-injection", "a.go", 7, "open")},
+			wantUnmatched: []int{4},
+		},
+		{
+			name:      "each dismissed alert is used once by fingerprint",
+			dismissed: []Alert{testAlert(5, "go/xss", "a.go", 1, "dismissed")},
+			open: []Alert{
+				testAlert(15, "go/xss", "a.go", 1, "open"),
+				testAlert(16, "go/xss", "b.go", 1, "open"),
+			},
+			from:        map[int]string{5: "go/xss\x00h5"},
+			to:          map[int]string{15: "go/xss\x00h5", 16: "go/xss\x00h5"},
+			wantChanges: []string{"15 <- fingerprint (#5)"},
+		},
+		{
+			name:      "each dismissed alert is used once by location",
+			dismissed: []Alert{testAlert(6, "go/xss", "a.go", 1, "dismissed")},
+			open: []Alert{
+				testAlert(17, "go/xss", "a.go", 1, "open"),
+				testAlert(18, "go/xss", "a.go", 1, "open"),
+			},
+			wantChanges: []string{"17 <- location (#6)"},
+		},
+		{
+			name: "a fingerprint match isn't reused by location",
+			dismissed: []Alert{
+				testAlert(7, "go/xss", "a.go", 1, "dismissed"),
+				testAlert(8, "go/xss", "a.go", 1, "dismissed"),
+			},
+			open: []Alert{
+				testAlert(19, "go/xss", "c.go", 9, "open"),
+				testAlert(20, "go/xss", "a.go", 1, "open"),
+			},
+			from:        map[int]string{7: "go/xss\x00h7"},
+			to:          map[int]string{19: "go/xss\x00h7"},
+			wantChanges: []string{"19 <- fingerprint (#7)", "20 <- location (#8)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, unmatched := matchDismissals(tt.dismissed, tt.open, tt.from, tt.to)
+
+			var got []string
+			for _, c := range changes {
+				got = append(got, fmt.Sprintf("%v <- %v", c.Number, c.Match))
+				if c.State != "dismissed" || c.PreviousState != "open" {
+					t.Errorf("alert %v: state %q -> %q, want open -> dismissed", c.Number, c.PreviousState, c.State)
+				}
+				if c.DismissedReason != "false positive" || c.DismissedComment == "" {
+					t.Errorf("alert %v: reason %q, comment %q not copied from the dismissed alert", c.Number, c.DismissedReason, c.DismissedComment)
+				}
+			}
+			if !slices.Equal(got, tt.wantChanges) {
+				t.Errorf("changes = %q, want %q", got, tt.wantChanges)
+			}
+
+			var gotUnmatched []int
+			for _, a := range unmatched {
+				gotUnmatched = append(gotUnmatched, a.Number)
+			}
+			if !slices.Equal(gotUnmatched, tt.wantUnmatched) {
+				t.Errorf("unmatched = %v, want %v", gotUnmatched, tt.wantUnmatched)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/browser"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/spf13/cobra"
+)
+
+var alertsWebFlag bool
+
+// alertsViewCmd represents the alerts view command
+var alertsViewCmd = &cobra.Command{
+	Use:   "view <alert-number>",
+	Short: "View a code scanning alert",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Setup Repository
+		repo, err := GetRepository()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if err := checkJSONFields(jsonFields(Alert{})); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		client, err := newRESTClient(repo, nil)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		a, raw, err := getAlert(client, repo, args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if alertsWebFlag {
+			if err := browser.New("", os.Stdout, os.Stderr).Browse(a.HTMLURL); err != nil {
+				fmt.Println(err)
+			}
+			return
+		}
+
+		if jsonOutput() {
+			if err := printJSON(raw); err != nil {
+				fmt.Println(err)
+			}
+			return
+		}
+
+		if err := printAlert(term.FromEnv(), a); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+// printAlert prints an alert, its most recent instance and its rule's help.
+func printAlert(terminal term.Term, a Alert) error {
+	w := terminal.Out()
+	bold := func(s string) string {
+		if terminal.IsColorEnabled() {
+			return "\033[1m" + s + "\033[0m"
+		}
+		return s
+	}
+	severity := a.severity()
+	if terminal.IsColorEnabled() {
+		severity = alertSeverityColor(severity)(severity)
+	}
+
+	fmt.Fprintln(w, bold(fmt.Sprintf("#%v %v (%v)", a.Number, a.Rule.ID, a.State)))
+	if a.Rule.Description != "" {
+		fmt.Fprintln(w, a.Rule.Description)
+	}
+	fmt.Fprintln(w)
+
+	instance := a.MostRecentInstance
+	tool := a.Tool.Name
+	if a.Tool.Version != "" {
+		tool += "@" + a.Tool.Version
+	}
+	fields := [][2]string{
+		{"Severity", severity},
+		{"Tool", tool},
+		{"Location", instance.Location.String()},
+		{"Ref", instance.Ref},
+		{"Commit", instance.CommitSha},
+		{"Category", instance.Category},
+		{"Created", a.CreatedAt},
+		{"Updated", a.UpdatedAt},
+		{"Fixed", a.FixedAt},
+		{"Tags", strings.Join(a.Rule.Tags, ", ")},
+	}
+	if a.State == "dismissed" {
+		by := ""
+		if a.DismissedBy != nil {
+			by = a.DismissedBy.Login
+		}
+		fields = append(fields,
+			[2]string{"Dismissed", strings.TrimSpace(fmt.Sprintf("%v by %v", a.DismissedAt, by))},
+			[2]string{"Reason", a.DismissedReason},
+			[2]string{"Comment", a.DismissedComment},
+		)
+	}
+	fields = append(fields, [2]string{"URL", a.HTMLURL})
+	for _, f := range fields {
+		if f[1] != "" {
+			fmt.Fprintf(w, "%v %v\n", bold(f[0]+":"), f[1])
+		}
+	}
+
+	if instance.Message.Text != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, instance.Message.Text)
+	}
+	if a.Rule.Help != "" {
+		fmt.Fprintln(w)
+		return printMarkdown(w, terminal, a.Rule.Help)
+	}
+	return nil
+}
+
+func init() {
+	alertsCmd.AddCommand(alertsViewCmd)
+	setJSONFields(alertsViewCmd, Alert{})
+	alertsViewCmd.Flags().BoolVarP(&alertsWebFlag, "web", "w", false, "Open the alert in the browser")
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// recordingTransport sends every request to a local test server, recording the host it was meant for.
+type recordingTransport struct {
+	server *url.URL
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.requests = append(rt.requests, req.Clone(req.Context()))
+	rt.mu.Unlock()
+
+	req = req.Clone(req.Context())
+	req.URL.Scheme = rt.server.Scheme
+	req.URL.Host = rt.server.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newTestServer(t *testing.T) *recordingTransport {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{prefix...}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if base := filepath.Base(r.URL.Path); base == "analyses" || base == "alerts" || base == "instances" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`{"version":"2.1.0","runs":[]}`))
+	})
+	mux.HandleFunc("DELETE /{prefix...}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"next_analysis_url":"","confirm_delete_url":""}`))
+	})
+	mux.HandleFunc("PATCH /{prefix...}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"number":1,"state":"dismissed"}`))
+	})
+	mux.HandleFunc("POST /{prefix...}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"id":"abc","url":"https://example.com"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := &recordingTransport{server: u}
+	clientTransport = rt
+	t.Cleanup(func() { clientTransport = nil })
+
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Setenv("GH_TOKEN", "token")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "token")
+	return rt
+}
+
+func TestCommandsUseRepositoryHost(t *testing.T) {
+	sarifFile := filepath.Join(t.TempDir(), "results.sarif")
+	if err := os.WriteFile(sarifFile, []byte(`{"version":"2.1.0","runs":[]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sha := "0123456789abcdef0123456789abcdef01234567"
+
+	commands := map[string][]string{
+		"list":   {"list"},
+		"view":   {"view", "1"},
+		"delete": {"delete", "1"},
+		"upload": {"upload", sha, "refs/heads/main", sarifFile},
+		"export": {"export", "--out", t.TempDir()},
+
+		"alerts list":      {"alerts", "list"},
+		"alerts view":      {"alerts", "view", "1"},
+		"alerts dismiss":   {"alerts", "dismiss", "1", "--reason", "false-positive"},
+		"alerts reopen":    {"alerts", "reopen", "1"},
+		"alerts instances": {"alerts", "instances", "1"},
+	}
+	hosts := []struct {
+		repo     string
+		wantHost string
+		wantPath string
+	}{
+		{repo: "owner/repo", wantHost: "api.github.com", wantPath: "/repos/owner/repo/"},
+		{repo: "ghes.example.com/owner/repo", wantHost: "ghes.example.com", wantPath: "/api/v3/repos/owner/repo/"},
+	}
+
+	for name, args := range commands {
+		for _, h := range hosts {
+			t.Run(name+" "+h.repo, func(t *testing.T) {
+				rt := newTestServer(t)
+				rootCmd.SetArgs(append(args, "--repo", h.repo))
+				if err := rootCmd.Execute(); err != nil {
+					t.Fatal(err)
+				}
+
+				if len(rt.requests) == 0 {
+					t.Fatal("no requests were made")
+				}
+				for _, req := range rt.requests {
+					if req.URL.Host != h.wantHost {
+						t.Errorf("request to %v, want host %v", req.URL, h.wantHost)
+					}
+					if len(req.URL.Path) < len(h.wantPath) || req.URL.Path[:len(h.wantPath)] != h.wantPath {
+						t.Errorf("request to %v, want path prefix %v", req.URL, h.wantPath)
+					}
+					if got := req.Header.Get("X-GitHub-Api-Version"); got != apiVersion {
+						t.Errorf("X-GitHub-Api-Version = %q, want %q", got, apiVersion)
+					}
+				}
+			})
+		}
+	}
+}
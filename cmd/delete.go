@@ -27,11 +27,9 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 
 	"github.com/cli/go-gh/v2/pkg/api"
-	"github.com/cli/go-gh/v2/pkg/jsonpretty"
 	"github.com/spf13/cobra"
 )
 
@@ -41,19 +39,19 @@ type deletedOK struct {
 	ConfirmDelete string `json:"confirm_delete_url"`
 }
 
+// Represents an analysis that was deleted, as output with --json
+type deletedAnalysis struct {
+	ID string `json:"id"`
+	deletedOK
+}
+
 // deleteAnalysis sends a DELETE request to the GitHub API to delete an analysis.
-func deleteAnalysis(a string) (*http.Response, error) {
+func deleteAnalysis(client *api.RESTClient, a string) (*http.Response, error) {
 	u, err := url.Parse(a)
 	if err != nil {
 		return nil, err
 	}
 
-	var opts api.ClientOptions
-	client, err := api.NewRESTClient(opts)
-	if err != nil {
-		return nil, err
-	}
-
 	response, err := client.Request(http.MethodDelete, u.String(), nil)
 	if err != nil {
 		// A 400 will indicate that the analysis is not deletable and is probably not the most recent in the set.
@@ -69,11 +67,11 @@ func deleteAnalysis(a string) (*http.Response, error) {
 
 // deleteAllAnalyses sends DELETE requests to the GitHub API to delete all analyses in a set.
 // Respects the --confirm-delete flag if set.
-// Returns a slice of the analysis IDs that were deleted.
-func deleteAllAnalyses(u string) ([]string, error) {
-	var deletedAnalyses []string
+// Returns a slice of the analyses that were deleted.
+func deleteAllAnalyses(client *api.RESTClient, u string) ([]deletedAnalysis, error) {
+	var deletedAnalyses []deletedAnalysis
 	for {
-		r, err := deleteAnalysis(u)
+		r, err := deleteAnalysis(client, u)
 		if err != nil {
 			// A 400 will indicate that the analysis is not deletable, and there is nothing left to do.
 			// This is not an error, and indicates there are no other analyses to delete.
@@ -98,7 +96,7 @@ func deleteAllAnalyses(u string) ([]string, error) {
 			return nil, err
 		}
 		us := strings.Split(analysisURL.Path, "/")
-		deletedAnalyses = append(deletedAnalyses, us[len(us)-1])
+		deletedAnalyses = append(deletedAnalyses, deletedAnalysis{ID: us[len(us)-1], deletedOK: n})
 		// Use confirm delete url if --confirm-delete was used.
 		if confirmDeleteFlag {
 			// If there are no more analyses to delete, break the loop.
@@ -144,6 +142,17 @@ var deleteCmd = &cobra.Command{
 			return
 		}
 
+		if err := checkJSONFields(jsonFields(deletedAnalysis{})); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		client, err := newRESTClient(repo, nil)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
 		// Making purge an alias for --delete-all --confirm-delete
 		if purgeFlag {
 			deleteAllFlag = true
@@ -157,7 +166,7 @@ var deleteCmd = &cobra.Command{
 		}
 
 		// Delete all analyses provided in args.
-		var deletedAnalyses []string
+		var deletedAnalyses []deletedAnalysis
 		for _, arg := range args {
 			baseURL := fmt.Sprintf("repos/%v/%v/code-scanning/analyses/%v", repo.Owner, repo.Name, arg)
 
@@ -168,7 +177,7 @@ var deleteCmd = &cobra.Command{
 				if confirmDeleteFlag {
 					opts = "?confirm_delete"
 				}
-				n, err := deleteAllAnalyses(baseURL + opts)
+				n, err := deleteAllAnalyses(client, baseURL+opts)
 				if err != nil {
 					fmt.Println(err)
 					return
@@ -180,36 +189,38 @@ var deleteCmd = &cobra.Command{
 			// Delete a single analysis
 			var r *http.Response
 			if confirmDeleteFlag {
-				r, err = deleteAnalysis(baseURL + `?confirm_delete`)
+				r, err = deleteAnalysis(client, baseURL+`?confirm_delete`)
 			} else {
-				r, err = deleteAnalysis(baseURL)
+				r, err = deleteAnalysis(client, baseURL)
 			}
 			if err != nil {
 				fmt.Println(err)
 				return
 			}
-			deletedAnalyses = append(deletedAnalyses, arg)
-
 			d, err := getDeleteResponse(r)
 			if err != nil {
 				fmt.Println(err)
 				return
 			}
-			if jsonFlag {
-				jsonpretty.Format(os.Stdout, r.Body, "\t", true)
-				return
+			deletedAnalyses = append(deletedAnalyses, deletedAnalysis{ID: arg, deletedOK: d})
+			if jsonOutput() {
+				continue
 			}
-			fmt.Printf("Successfully deleted analysis %v", arg)
+			fmt.Printf("Successfully deleted analysis %v\n", arg)
 			if d.NextAnalysis != "" {
 				fmt.Printf("Next analysis: %v\n", d.NextAnalysis)
 			} else {
 				fmt.Println("Next analysis: None (last in set)")
 			}
 		}
-		if jsonFlag {
+		if jsonOutput() {
+			if deletedAnalyses == nil {
+				deletedAnalyses = []deletedAnalysis{}
+			}
 			j, _ := json.Marshal(deletedAnalyses)
-			reader := strings.NewReader(string(j))
-			jsonpretty.Format(os.Stdout, reader, "\t", true)
+			if err := printJSON(j); err != nil {
+				fmt.Println(err)
+			}
 			return
 		}
 		fmt.Printf("Successfully deleted %v analyses.\n", len(deletedAnalyses))
@@ -222,6 +233,7 @@ var purgeFlag bool
 
 func init() {
 	rootCmd.AddCommand(deleteCmd)
+	setJSONFields(deleteCmd, deletedAnalysis{})
 
 	deleteCmd.Flags().BoolVar(&deleteAllFlag, "delete-all", false, "Delete all analyses in the set, except the last.")
 	deleteCmd.Flags().BoolVar(&confirmDeleteFlag, "confirm-delete", false, "Allow the deletion of the last analysis in the set.")
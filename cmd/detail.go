@@ -0,0 +1,227 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/markdown"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+// Matches embedded links in SARIF messages: [text](target), where text may contain escaped brackets.
+var messageLinkRE = regexp.MustCompile(`\[((?:\\.|[^\]\\])*)\]\(([^)]*)\)`)
+
+// Matches CWE tags such as external/cwe/cwe-079.
+var cweTagRE = regexp.MustCompile(`(?i)^external/cwe/cwe-0*(\d+)$`)
+
+// fullMessage returns the result's message with its {n} placeholders filled from message.arguments
+// and links to its locations replaced with the path and line they point to.
+func (row resultRow) fullMessage() string {
+	text := row.message()
+	// A message may be given by ID from the rule's messageStrings instead of text.
+	if text == "" && row.result.Message.ID != nil {
+		if rule := row.rule(); rule != nil && rule.MessageStrings != nil {
+			if m, ok := (*rule.MessageStrings)[*row.result.Message.ID]; ok {
+				text = multiformatText(&m)
+			}
+		}
+	}
+	text = fillPlaceholders(text, row.result.Message.Arguments)
+	text = resolveMessageLinks(text, row)
+	// Brackets that aren't part of a link are escaped in SARIF messages.
+	return strings.NewReplacer(`\[`, "[", `\]`, "]").Replace(text)
+}
+
+// multiformatText returns the text of a multiformat message, falling back to its markdown.
+func multiformatText(m *sarif.MultiformatMessageString) string {
+	if m == nil {
+		return ""
+	}
+	if m.Text != nil {
+		return *m.Text
+	}
+	if m.Markdown != nil {
+		return *m.Markdown
+	}
+	return ""
+}
+
+// fillPlaceholders replaces {n} in a message with the nth argument. {{ and }} are literal braces.
+func fillPlaceholders(text string, args []string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if (c == '{' || c == '}') && i+1 < len(text) && text[i+1] == c {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		if c == '{' {
+			if end := strings.IndexByte(text[i:], '}'); end > 1 {
+				if n, err := strconv.Atoi(text[i+1 : i+end]); err == nil && n >= 0 && n < len(args) {
+					b.WriteString(args[n])
+					i += end
+					continue
+				}
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// resolveMessageLinks replaces links to location IDs with the link text followed by the location.
+// Links to URLs are left as they are.
+func resolveMessageLinks(text string, row resultRow) string {
+	return messageLinkRE.ReplaceAllStringFunc(text, func(link string) string {
+		m := messageLinkRE.FindStringSubmatch(link)
+		id, err := strconv.Atoi(m[2])
+		if err != nil {
+			return link
+		}
+		label := strings.NewReplacer(`\[`, "[", `\]`, "]").Replace(m[1])
+		for _, loc := range slices.Concat(row.result.RelatedLocations, row.result.Locations) {
+			if loc != nil && loc.Id != nil && int(*loc.Id) == id {
+				if l := formatLocation(row.run, loc.PhysicalLocation); l != "" {
+					return fmt.Sprintf("%v (%v)", label, l)
+				}
+			}
+		}
+		return label
+	})
+}
+
+// cweIDs returns the CWE IDs from a list of tags, such as CWE-79 for external/cwe/cwe-079.
+func cweIDs(tags []string) []string {
+	var ids []string
+	for _, t := range tags {
+		if m := cweTagRE.FindStringSubmatch(t); m != nil {
+			ids = append(ids, "CWE-"+m[1])
+		}
+	}
+	return ids
+}
+
+// printResultDetail prints everything about a result: its full message, source, and the rule's metadata and help.
+func printResultDetail(terminal term.Term, row resultRow, context int) error {
+	w := terminal.Out()
+	color := terminal.IsColorEnabled()
+	bold := func(s string) string {
+		if color {
+			return "\033[1m" + s + "\033[0m"
+		}
+		return s
+	}
+	rule := row.rule()
+
+	fmt.Fprintln(w, bold(fmt.Sprintf("%v  %v  %v", orDash(row.ruleID()), row.severity(), orDash(row.location()))))
+	if rule != nil && rule.ShortDescription != nil {
+		fmt.Fprintln(w, multiformatText(rule.ShortDescription))
+	}
+	fmt.Fprintln(w)
+	if err := printMarkdown(w, terminal, row.fullMessage()); err != nil {
+		return err
+	}
+
+	if loc := row.primaryLocation(); loc != nil {
+		if s, err := loadSnippet(row.run, loc, context); err == nil {
+			fmt.Fprintln(w)
+			if err := printSnippet(w, s, color); err != nil {
+				return err
+			}
+		}
+	}
+
+	var fields [][2]string
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, [2]string{name, value})
+		}
+	}
+	add("Level", row.effectiveLevel())
+	add("Alert", row.alertNumber())
+	add("Tool", row.tool())
+	add("Category", row.category())
+	if rule != nil {
+		if rule.Name != nil {
+			add("Rule name", *rule.Name)
+		}
+		if p, ok := rule.Properties["precision"]; ok {
+			add("Precision", fmt.Sprint(p))
+		}
+	}
+	add("Security severity", row.securitySeverity())
+	tags := row.tags()
+	add("Tags", strings.Join(tags, ", "))
+	add("CWE", strings.Join(cweIDs(tags), ", "))
+	if rule != nil && rule.HelpURI != nil {
+		add("Help", *rule.HelpURI)
+	}
+	fmt.Fprintln(w)
+	for _, f := range fields {
+		fmt.Fprintf(w, "%v %v\n", bold(f[0]+":"), f[1])
+	}
+	if n := row.alertNumber(); n != "" {
+		fmt.Fprintf(w, "Use \"gh sarif alerts view %v\" to triage the alert.\n", n)
+	}
+
+	if rule == nil {
+		return nil
+	}
+	if d := multiformatText(rule.FullDescription); d != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, d)
+	}
+	if rule.Help != nil {
+		fmt.Fprintln(w)
+		if rule.Help.Markdown != nil {
+			return printMarkdown(w, terminal, *rule.Help.Markdown)
+		}
+		fmt.Fprintln(w, multiformatText(rule.Help))
+	}
+	return nil
+}
+
+// printMarkdown renders markdown for the terminal, or writes it as it is when color is disabled.
+func printMarkdown(w io.Writer, terminal term.Term, text string) error {
+	if !terminal.IsColorEnabled() {
+		_, err := fmt.Fprintln(w, text)
+		return err
+	}
+	width, _, err := terminal.Size()
+	if err != nil || width <= 0 {
+		width = 80
+	}
+	out, err := markdown.Render(text, markdown.WithTheme("dark"), markdown.WithWrap(width))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, out)
+	return err
+}
@@ -0,0 +1,288 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+var exportOutFlag string
+var exportThrottleFlag time.Duration
+
+// The name of the manifest written to the root of an export, and the directory holding the analyses.
+const (
+	exportManifestName = "index.json"
+	exportAnalysesDir  = "analyses"
+)
+
+// exportManifest is the index of an exported analysis history.
+type exportManifest struct {
+	Repository string          `json:"repository"`
+	ExportedAt string          `json:"exported_at"`
+	Analyses   []exportedFiles `json:"analyses"`
+}
+
+// exportedFiles records the files of an exported analysis, with their SHA-256 checksums.
+type exportedFiles struct {
+	ID          int    `json:"id"`
+	CreatedAt   string `json:"created_at"`
+	Ref         string `json:"ref"`
+	Tool        string `json:"tool"`
+	Category    string `json:"category"`
+	Analysis    string `json:"analysis"`
+	AnalysisSHA string `json:"analysis_sha256"`
+	Sarif       string `json:"sarif"`
+	SarifSHA    string `json:"sarif_sha256"`
+	Skipped     bool   `json:"skipped"`
+}
+
+// sha256Hex returns the hex encoded SHA-256 checksum of b.
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// exportFile writes a file of the export, unless it was written by an earlier run.
+// It returns the checksum of the file, and whether it already existed.
+func exportFile(dir, name string, fetch func() ([]byte, error)) (string, bool, error) {
+	path := filepath.Join(dir, name)
+	if b, err := os.ReadFile(path); err == nil {
+		return sha256Hex(b), true, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", false, err
+	}
+	b, err := fetch()
+	if err != nil {
+		return "", false, err
+	}
+	if err := writeFileAtomic(path, b); err != nil {
+		return "", false, err
+	}
+	return sha256Hex(b), false, nil
+}
+
+// downloadSarif downloads the SARIF of an analysis.
+func downloadSarif(client *api.RESTClient, path string) ([]byte, error) {
+	response, err := requestWithRetry(client, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	return io.ReadAll(response.Body)
+}
+
+// removeTempFiles removes the temporary files left in dir by an interrupted export.
+func removeTempFiles(dir string) error {
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp"))
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		if err := os.Remove(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export --out <dir>",
+	Short: "Export every GitHub Code Scanning analysis of a repository to a directory",
+	Long: fmt.Sprintf(`Export the full analysis history of a repository, e.g. as a backup before "gh sarif delete --purge".
+
+	The metadata and SARIF of each analysis are written to %[1]v/<id>.json and %[1]v/<id>.sarif,
+	and %[2]v lists every analysis with the SHA-256 checksums of its files.
+
+	Files already in the directory are not downloaded again, so an interrupted export can be resumed by
+	running the same command. SARIF downloads are spaced out by --throttle, and rate limited requests are retried.`, exportAnalysesDir, exportManifestName),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Setup Repository
+		repo, err := GetRepository()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if err := checkJSONFields(jsonFields(exportedFiles{})); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if exportOutFlag == "" {
+			fmt.Println("--out is required")
+			return
+		}
+
+		analysesDir := filepath.Join(exportOutFlag, exportAnalysesDir)
+		if err := os.MkdirAll(analysesDir, 0755); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := removeTempFiles(analysesDir); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		client, err := newRESTClient(repo, nil)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		sarifClient, err := newRESTClient(repo, map[string]string{"Accept": "application/sarif+json"})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		baseURL := fmt.Sprintf("repos/%v/%v/code-scanning/analyses", repo.Owner, repo.Name)
+		params := url.Values{}
+		params.Add("per_page", strconv.Itoa(maxPerPage))
+		u := url.URL{Path: baseURL, RawQuery: params.Encode()}
+
+		manifest := exportManifest{
+			Repository: fmt.Sprintf("%v/%v/%v", repo.Host, repo.Owner, repo.Name),
+			ExportedAt: time.Now().UTC().Format(time.RFC3339),
+			Analyses:   []exportedFiles{},
+		}
+		var lastDownload time.Time
+		failed := 0
+		err = fetchPages(client, u.String(), true, func(body []byte) (bool, error) {
+			var page []json.RawMessage
+			if err := json.Unmarshal(body, &page); err != nil {
+				return false, err
+			}
+			for _, raw := range page {
+				var analysis Analysis
+				if err := json.Unmarshal(raw, &analysis); err != nil {
+					return false, err
+				}
+				files := exportedFiles{
+					ID:        analysis.ID,
+					CreatedAt: analysis.CreatedAt,
+					Ref:       analysis.Ref,
+					Tool:      fmt.Sprintf("%v@%v", analysis.Tool.Name, analysis.Tool.Version),
+					Category:  analysis.Category,
+					Analysis:  filepath.ToSlash(filepath.Join(exportAnalysesDir, fmt.Sprintf("%v.json", analysis.ID))),
+					Sarif:     filepath.ToSlash(filepath.Join(exportAnalysesDir, fmt.Sprintf("%v.sarif", analysis.ID))),
+				}
+
+				var metadataExisted, sarifExisted bool
+				files.AnalysisSHA, metadataExisted, err = exportFile(exportOutFlag, files.Analysis, func() ([]byte, error) {
+					var b bytes.Buffer
+					if err := json.Indent(&b, raw, "", "  "); err != nil {
+						return nil, err
+					}
+					return append(b.Bytes(), '\n'), nil
+				})
+				if err != nil {
+					return false, err
+				}
+				files.SarifSHA, sarifExisted, err = exportFile(exportOutFlag, files.Sarif, func() ([]byte, error) {
+					// Space out the SARIF downloads to stay under the secondary rate limits.
+					time.Sleep(time.Until(lastDownload.Add(exportThrottleFlag)))
+					lastDownload = time.Now()
+					return downloadSarif(sarifClient, fmt.Sprintf("%v/%v", baseURL, analysis.ID))
+				})
+				if err != nil {
+					// A single analysis without a SARIF download shouldn't stop the export.
+					fmt.Fprintf(os.Stderr, "Failed to export analysis %v: %v\n", analysis.ID, err)
+					failed++
+					continue
+				}
+				files.Skipped = metadataExisted && sarifExisted
+				manifest.Analyses = append(manifest.Analyses, files)
+
+				if jsonOutput() {
+					continue
+				}
+				if files.Skipped {
+					fmt.Printf("Skipped analysis %v (already exported)\n", analysis.ID)
+				} else {
+					fmt.Printf("Exported analysis %v\n", analysis.ID)
+				}
+			}
+			return len(page) > 0, nil
+		})
+		if err != nil {
+			fmt.Println(err)
+			fmt.Println("Run the same command again to resume the export.")
+			os.Exit(1)
+		}
+
+		b, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := writeFileAtomic(filepath.Join(exportOutFlag, exportManifestName), append(b, '\n')); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if jsonOutput() {
+			b, err := json.Marshal(manifest.Analyses)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if err := printJSON(b); err != nil {
+				fmt.Println(err)
+			}
+		} else {
+			skipped := 0
+			for _, files := range manifest.Analyses {
+				if files.Skipped {
+					skipped++
+				}
+			}
+			fmt.Printf("Exported %v analyses to %v (%v already present).\n", len(manifest.Analyses), exportOutFlag, skipped)
+		}
+		if failed > 0 {
+			fmt.Printf("Failed to export %v analyses. Run the same command again to retry them.\n", failed)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	setJSONFields(exportCmd, exportedFiles{})
+
+	exportCmd.Flags().StringVarP(&exportOutFlag, "out", "o", "", "Directory to export the analyses to (required).")
+	exportCmd.Flags().DurationVar(&exportThrottleFlag, "throttle", time.Second, "Minimum time between SARIF downloads.")
+}
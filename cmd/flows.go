@@ -0,0 +1,178 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+// threadFlow is one thread flow of a result's code flows, as output by --flows.
+type threadFlow struct {
+	CodeFlow   int        `json:"codeFlow"`
+	ThreadFlow int        `json:"threadFlow"`
+	Message    string     `json:"message"`
+	Steps      []flowStep `json:"steps"`
+}
+
+// flowStep is a location visited by a thread flow.
+type flowStep struct {
+	Step        int      `json:"step"`
+	Location    string   `json:"location"`
+	Path        string   `json:"path"`
+	StartLine   int      `json:"startLine"`
+	StartColumn int      `json:"startColumn"`
+	Message     string   `json:"message"`
+	Kinds       []string `json:"kinds"`
+	Snippet     string   `json:"snippet"`
+
+	physical *sarif.PhysicalLocation
+}
+
+// selectResult finds the result with an index as shown in the # column or, if byAlert is set,
+// the result of a GitHub alert number.
+func selectResult(rows []resultRow, byAlert bool, index int, alert int) (resultRow, error) {
+	for _, row := range rows {
+		if byAlert && row.alertNumber() == strconv.Itoa(alert) {
+			return row, nil
+		}
+		if !byAlert && row.index == index {
+			return row, nil
+		}
+	}
+	if byAlert {
+		return resultRow{}, fmt.Errorf("no result for alert %v", alert)
+	}
+	return resultRow{}, fmt.Errorf("no result with index %v", index)
+}
+
+// threadFlows returns every thread flow of a result, numbering their steps.
+func (row resultRow) threadFlows() []threadFlow {
+	var flows []threadFlow
+	for c, cf := range row.result.CodeFlows {
+		if cf == nil {
+			continue
+		}
+		for t, tf := range cf.ThreadFlows {
+			if tf == nil {
+				continue
+			}
+			flow := threadFlow{CodeFlow: c + 1, ThreadFlow: t + 1, Message: messageText(tf.Message), Steps: []flowStep{}}
+			if flow.Message == "" {
+				flow.Message = messageText(cf.Message)
+			}
+			for _, tfl := range tf.Locations {
+				flow.Steps = append(flow.Steps, row.flowStep(tfl, len(flow.Steps)+1))
+			}
+			flows = append(flows, flow)
+		}
+	}
+	return flows
+}
+
+// flowStep describes a thread flow location. Locations may refer to an entry of run.threadFlowLocations.
+func (row resultRow) flowStep(tfl *sarif.ThreadFlowLocation, n int) flowStep {
+	step := flowStep{Step: n, Kinds: []string{}}
+	if tfl == nil {
+		return step
+	}
+	loc := tfl.Location
+	if tfl.Index != nil && *tfl.Index >= 0 && *tfl.Index < len(row.run.ThreadFlowLocations) {
+		shared := row.run.ThreadFlowLocations[*tfl.Index]
+		if loc == nil && shared != nil {
+			loc = shared.Location
+		}
+		if len(tfl.Kinds) == 0 && shared != nil {
+			tfl = shared
+		}
+	}
+	if tfl.Kinds != nil {
+		step.Kinds = tfl.Kinds
+	}
+	if loc == nil {
+		return step
+	}
+	step.Message = messageText(loc.Message)
+	if p := loc.PhysicalLocation; p != nil {
+		step.physical = p
+		step.Location = formatLocation(row.run, p)
+		step.Path = artifactPath(row.run, p.ArtifactLocation)
+		if p.Region != nil && p.Region.StartLine != nil {
+			step.StartLine = *p.Region.StartLine
+			if p.Region.StartColumn != nil {
+				step.StartColumn = *p.Region.StartColumn
+			}
+		}
+		if s, err := loadSnippet(row.run, p, 0); err == nil {
+			step.Snippet = strings.Join(s.lines, "\n")
+		}
+	}
+	return step
+}
+
+// printThreadFlows prints the thread flows of a result as numbered steps, with the source of each step.
+func printThreadFlows(w io.Writer, row resultRow, flows []threadFlow, context int, color bool) error {
+	bold := func(s string) string {
+		if color {
+			return "\033[1m" + s + "\033[0m"
+		}
+		return s
+	}
+	fmt.Fprintln(w, bold(fmt.Sprintf("%v  %v  %v", orDash(row.ruleID()), row.severity(), orDash(row.location()))))
+	fmt.Fprintln(w, row.fullMessage())
+	if len(flows) == 0 {
+		fmt.Fprintln(w, "\nThe result has no code flows.")
+		return nil
+	}
+	for _, flow := range flows {
+		fmt.Fprintln(w)
+		heading := fmt.Sprintf("Code flow %v, thread flow %v: %v steps", flow.CodeFlow, flow.ThreadFlow, len(flow.Steps))
+		if flow.Message != "" {
+			heading += " - " + flow.Message
+		}
+		fmt.Fprintln(w, bold(heading))
+		for _, step := range flow.Steps {
+			fmt.Fprintln(w)
+			line := fmt.Sprintf("%v. %v", step.Step, orDash(step.Location))
+			if len(step.Kinds) > 0 {
+				line += fmt.Sprintf(" (%v)", strings.Join(step.Kinds, ", "))
+			}
+			fmt.Fprintln(w, bold(line))
+			if step.Message != "" {
+				fmt.Fprintln(w, step.Message)
+			}
+			if step.physical == nil {
+				continue
+			}
+			if s, err := loadSnippet(row.run, step.physical, context); err == nil {
+				if err := printSnippet(w, s, color); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
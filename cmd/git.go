@@ -0,0 +1,92 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var commitShaRE = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+var refRE = regexp.MustCompile(`^refs/(heads|tags)/.+$|^refs/pull/[0-9]+/(merge|head)$`)
+
+// validateCommitSha checks that sha is a full 40 character hex commit SHA.
+func validateCommitSha(sha string) error {
+	if !commitShaRE.MatchString(sha) {
+		return fmt.Errorf("invalid commit SHA %q: must be a full 40 character hex SHA", sha)
+	}
+	return nil
+}
+
+// validateRef checks that ref is in one of the formats accepted by the SARIF upload API.
+func validateRef(ref string) error {
+	if !refRE.MatchString(ref) {
+		return fmt.Errorf("invalid ref %q: must be refs/heads/<branch>, refs/tags/<tag> or refs/pull/<number>/merge|head", ref)
+	}
+	return nil
+}
+
+// isGitHubActions reports whether we are running inside a GitHub Actions workflow.
+func isGitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// inferCommitSha returns the commit SHA being analyzed.
+// Under GitHub Actions this is GITHUB_SHA, otherwise HEAD of the local checkout.
+func inferCommitSha() (string, error) {
+	if sha := os.Getenv("GITHUB_SHA"); isGitHubActions() && sha != "" {
+		return sha, nil
+	}
+	sha, err := gitOutput("rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("could not determine commit SHA from the local checkout: %w", err)
+	}
+	return sha, nil
+}
+
+// inferRef returns the ref being analyzed.
+// Under GitHub Actions this is GITHUB_REF, otherwise the current branch of the local checkout.
+func inferRef() (string, error) {
+	if ref := os.Getenv("GITHUB_REF"); isGitHubActions() && ref != "" {
+		return ref, nil
+	}
+	ref, err := gitOutput("symbolic-ref", "--quiet", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("could not determine ref from the local checkout (detached HEAD?), please specify it explicitly: %w", err)
+	}
+	return ref, nil
+}
+
+// gitOutput runs git with the given arguments and returns its trimmed stdout.
+func gitOutput(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %v: %v", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
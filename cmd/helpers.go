@@ -23,9 +23,42 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/jq"
+	"github.com/cli/go-gh/v2/pkg/jsonpretty"
 	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/cli/go-gh/v2/pkg/template"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/spf13/cobra"
 )
 
+// The GitHub REST API version requested by every command.
+// https://docs.github.com/en/rest/about-the-rest-api/api-versions
+const apiVersion = "2022-11-28"
+
+// clientTransport replaces the HTTP transport of REST clients when set. Only used by tests.
+var clientTransport http.RoundTripper
+
+// stdinPath is the path argument that means stdin (or stdout for output).
+const stdinPath = "-"
+
 func GetRepository() (repository.Repository, error) {
 	var repo repository.Repository
 	var err error
@@ -36,3 +69,298 @@ func GetRepository() (repository.Repository, error) {
 	}
 	return repo, err
 }
+
+// newRESTClient builds the REST client used by every command.
+// Requests are sent to the repository's host (github.com or GHES), with the API version header
+// and any extra headers set.
+func newRESTClient(repo repository.Repository, headers map[string]string) (*api.RESTClient, error) {
+	opts := api.ClientOptions{
+		Host:      repo.Host,
+		Headers:   map[string]string{"X-GitHub-Api-Version": apiVersion},
+		Transport: clientTransport,
+	}
+	for k, v := range headers {
+		opts.Headers[k] = v
+	}
+	return api.NewRESTClient(opts)
+}
+
+// readSarifInput reads a SARIF log from a file, or from stdin if path is "-".
+// Gzipped input is decompressed transparently.
+func readSarifInput(path string) ([]byte, error) {
+	var r io.Reader
+	if path == stdinPath {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		return io.ReadAll(gr)
+	}
+	return io.ReadAll(br)
+}
+
+// writeOutput writes b to a file, or to stdout if path is "-".
+func writeOutput(path string, b []byte) error {
+	if path == stdinPath {
+		_, err := os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+// writeFileAtomic writes b to a temporary file next to path and renames it into place,
+// so that an interrupted write never leaves a partial file at path.
+func writeFileAtomic(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// nextPageRE matches the URL of the next page in a Link response header.
+var nextPageRE = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// How many times a rate limited request is retried, and the longest it's worth waiting for the limit to reset.
+const maxRateLimitRetries = 3
+const maxRateLimitWait = 15 * time.Minute
+
+// rateLimitDelay reports whether err is a rate limit response, and how long to wait before retrying.
+// https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api#exceeding-the-rate-limit
+func rateLimitDelay(err error) (time.Duration, bool) {
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) || (httpErr.StatusCode != http.StatusForbidden && httpErr.StatusCode != http.StatusTooManyRequests) {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(httpErr.Headers.Get("Retry-After")); err == nil {
+		return time.Duration(s) * time.Second, true
+	}
+	if httpErr.Headers.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(httpErr.Headers.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Until(time.Unix(reset, 0)), 0) + time.Second, true
+		}
+	}
+	// Secondary rate limits without a Retry-After header should wait at least a minute.
+	if httpErr.StatusCode == http.StatusTooManyRequests || strings.Contains(strings.ToLower(httpErr.Message), "secondary rate limit") {
+		return time.Minute, true
+	}
+	return 0, false
+}
+
+// requestWithRetry makes a request, waiting and retrying it when it's rate limited.
+func requestWithRetry(client *api.RESTClient, method, path string, body []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		var r io.Reader
+		if body != nil {
+			r = bytes.NewReader(body)
+		}
+		response, err := client.Request(method, path, r)
+		if err == nil {
+			return response, nil
+		}
+		delay, limited := rateLimitDelay(err)
+		if !limited || attempt >= maxRateLimitRetries || delay > maxRateLimitWait {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "Rate limited, retrying in %v\n", delay.Round(time.Second))
+		time.Sleep(delay)
+	}
+}
+
+// fetchPages requests path and calls fn with the body of the response.
+// If paginate is set, it then follows the Link headers of each response to request the following pages,
+// until there are none left or fn returns false. Rate limited requests are retried.
+func fetchPages(client *api.RESTClient, path string, paginate bool, fn func(body []byte) (bool, error)) error {
+	for path != "" {
+		response, err := requestWithRetry(client, http.MethodGet, path, nil)
+		if err != nil {
+			return err
+		}
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		more, err := fn(body)
+		if err != nil {
+			return err
+		}
+		if !more || !paginate {
+			return nil
+		}
+
+		path = ""
+		if m := nextPageRE.FindStringSubmatch(response.Header.Get("Link")); m != nil {
+			path = m[1]
+		}
+	}
+	return nil
+}
+
+// jsonOutput reports whether JSON output was requested, either directly or through --jq or --template.
+func jsonOutput() bool {
+	return len(jsonFlag) > 0 || jqFlag != "" || templateFlag != ""
+}
+
+// Annotation holding the JSON fields a command offers by default, listed when --json is given no value.
+const jsonFieldsAnnotation = "jsonFields"
+
+// setJSONFields records the JSON fields of v as the fields a command offers by default.
+func setJSONFields(cmd *cobra.Command, v interface{}) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[jsonFieldsAnnotation] = strings.Join(jsonFields(v), ",")
+}
+
+// jsonFields returns the JSON field names of a struct, in declaration order.
+// Fields of embedded structs are included as if they were fields of v itself, as encoding/json does.
+func jsonFields(v interface{}) []string {
+	return structJSONFields(reflect.TypeOf(v))
+}
+
+func structJSONFields(t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			fields = append(fields, structJSONFields(f.Type)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// jsonFlagError lists the available fields when --json is given without a value, like gh does.
+func jsonFlagError(cmd *cobra.Command, err error) error {
+	// pflag reports a missing value as "flag needs an argument: --json" or "flag needs an argument: 'j' in -j".
+	// Match the flag exactly, as other flags like --jq share the prefix.
+	msg := err.Error()
+	if msg != "flag needs an argument: --json" && !strings.HasPrefix(msg, "flag needs an argument: 'j' in -") {
+		return err
+	}
+	fields := strings.Split(cmd.Annotations[jsonFieldsAnnotation], ",")
+	if fields[0] == "" {
+		return errors.New("--json is not supported by this command")
+	}
+	cmd.SilenceUsage = true
+	return fmt.Errorf("Specify one or more comma-separated fields for `--json`:\n  %v", strings.Join(fields, "\n  "))
+}
+
+// checkJSONFields returns an error if --json requests a field that isn't available.
+func checkJSONFields(available []string) error {
+	for _, f := range jsonFlag {
+		if !slices.Contains(available, f) {
+			return fmt.Errorf("Unknown JSON field: %q\nAvailable fields:\n  %v", f, strings.Join(available, "\n  "))
+		}
+	}
+	return nil
+}
+
+// selectJSONFields keeps only the fields requested by --json in a JSON object, or in each object of a JSON array.
+func selectJSONFields(data []byte) ([]byte, error) {
+	if len(jsonFlag) == 0 {
+		return data, nil
+	}
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	selectFields := func(o map[string]interface{}) map[string]interface{} {
+		selected := map[string]interface{}{}
+		for _, f := range jsonFlag {
+			if fv, ok := o[f]; ok {
+				selected[f] = fv
+			}
+		}
+		return selected
+	}
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		v = selectFields(vv)
+	case []interface{}:
+		for i, e := range vv {
+			if o, ok := e.(map[string]interface{}); ok {
+				vv[i] = selectFields(o)
+			}
+		}
+	}
+	return json.Marshal(v)
+}
+
+// printJSON writes JSON data to stdout, keeping only the fields requested by --json,
+// then filtered through --jq or formatted with --template if set, and pretty-printed otherwise.
+func printJSON(data []byte) error {
+	data, err := selectJSONFields(data)
+	if err != nil {
+		return err
+	}
+	terminal := term.FromEnv()
+	colorize := terminal.IsTerminalOutput() && terminal.IsColorEnabled()
+	switch {
+	case jqFlag != "":
+		return jq.EvaluateFormatted(bytes.NewReader(data), terminal.Out(), jqFlag, "  ", colorize)
+	case templateFlag != "":
+		// Tables rendered by templates are truncated to the width, so don't let them collapse when piped.
+		width, _, err := terminal.Size()
+		if err != nil || width <= 0 {
+			width = 80
+		}
+		t := template.New(terminal.Out(), width, colorize)
+		if err := t.Parse(templateFlag); err != nil {
+			return err
+		}
+		if err := t.Execute(bytes.NewReader(data)); err != nil {
+			return err
+		}
+		return t.Flush()
+	default:
+		return jsonpretty.Format(terminal.Out(), bytes.NewReader(data), "\t", colorize)
+	}
+}
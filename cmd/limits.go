@@ -0,0 +1,265 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GitHub's documented limits for SARIF uploads.
+// https://docs.github.com/en/code-security/code-scanning/integrating-with-code-scanning/sarif-support-for-code-scanning#validating-your-sarif-file
+const (
+	maxGzipSize           = 10 * 1024 * 1024
+	maxRunsPerFile        = 20
+	maxResultsPerRun      = 25000
+	maxRulesPerRun        = 25000
+	maxLocationsPerResult = 1000
+	maxTagsPerRule        = 20
+)
+
+// limitViolation describes a part of a SARIF log that exceeds one of GitHub's limits.
+type limitViolation struct {
+	Path  string // JSON path of the offending value
+	What  string
+	Count int
+	Limit int
+}
+
+func (v limitViolation) String() string {
+	return fmt.Sprintf("%v: %v %v exceeds the limit of %v", v.Path, v.Count, v.What, v.Limit)
+}
+
+// checkGzipSize returns a violation if the gzipped SARIF log is larger than GitHub accepts.
+func checkGzipSize(size int) []limitViolation {
+	if size <= maxGzipSize {
+		return nil
+	}
+	return []limitViolation{{Path: "$", What: "bytes (gzipped)", Count: size, Limit: maxGzipSize}}
+}
+
+// checkLimits returns every part of a SARIF log that exceeds GitHub's limits, in document order.
+func checkLimits(doc map[string]interface{}) []limitViolation {
+	var violations []limitViolation
+	runs := jsonObjects(doc["runs"])
+	if len(runs) > maxRunsPerFile {
+		violations = append(violations, limitViolation{Path: "$.runs", What: "runs", Count: len(runs), Limit: maxRunsPerFile})
+	}
+
+	for i, run := range runs {
+		runPath := fmt.Sprintf("$.runs[%d]", i)
+
+		rules := 0
+		for _, c := range runToolComponents(run, runPath) {
+			rules += len(c.rules)
+			for j, rule := range c.rules {
+				if tags := ruleTags(rule); len(tags) > maxTagsPerRule {
+					violations = append(violations, limitViolation{
+						Path:  fmt.Sprintf("%v.rules[%d].properties.tags", c.path, j),
+						What:  "tags",
+						Count: len(tags),
+						Limit: maxTagsPerRule,
+					})
+				}
+			}
+		}
+		if rules > maxRulesPerRun {
+			violations = append(violations, limitViolation{Path: runPath + ".tool", What: "rules", Count: rules, Limit: maxRulesPerRun})
+		}
+
+		results := jsonObjects(run["results"])
+		if len(results) > maxResultsPerRun {
+			violations = append(violations, limitViolation{Path: runPath + ".results", What: "results", Count: len(results), Limit: maxResultsPerRun})
+		}
+		for j, result := range results {
+			if locations := jsonObjects(result["locations"]); len(locations) > maxLocationsPerResult {
+				violations = append(violations, limitViolation{
+					Path:  fmt.Sprintf("%v.results[%d].locations", runPath, j),
+					What:  "locations",
+					Count: len(locations),
+					Limit: maxLocationsPerResult,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// truncateToLimits trims a SARIF log so that it fits within GitHub's limits.
+// Excess items are always dropped from the end of their array, so the same input gives the same output.
+// Rules beyond the limit are dropped together with the results that refer to them.
+// Returns a description of everything that was dropped.
+func truncateToLimits(doc map[string]interface{}) []string {
+	var dropped []string
+	runs := jsonObjects(doc["runs"])
+	if len(runs) > maxRunsPerFile {
+		dropped = append(dropped, fmt.Sprintf("$.runs: dropped the last %d of %d runs", len(runs)-maxRunsPerFile, len(runs)))
+		runs = runs[:maxRunsPerFile]
+		doc["runs"] = toJSONArray(runs)
+	}
+
+	for i, run := range runs {
+		runPath := fmt.Sprintf("$.runs[%d]", i)
+
+		// Rules are counted across the driver and all extensions, in that order.
+		remaining := maxRulesPerRun
+		droppedRules := map[string]bool{}
+		keptRules := map[string]int{} // Number of rules kept by each tool component whose rules were truncated, by path.
+		components := runToolComponents(run, runPath)
+		for _, c := range components {
+			for j, rule := range c.rules {
+				tags := ruleTags(rule)
+				if len(tags) > maxTagsPerRule {
+					rule["properties"].(map[string]interface{})["tags"] = tags[:maxTagsPerRule]
+					dropped = append(dropped, fmt.Sprintf("%v.rules[%d].properties.tags: dropped the last %d of %d tags", c.path, j, len(tags)-maxTagsPerRule, len(tags)))
+				}
+			}
+			if len(c.rules) > remaining {
+				for _, rule := range c.rules[remaining:] {
+					if id, ok := rule["id"].(string); ok {
+						droppedRules[id] = true
+					}
+				}
+				dropped = append(dropped, fmt.Sprintf("%v.rules: dropped the last %d of %d rules", c.path, len(c.rules)-remaining, len(c.rules)))
+				c.component["rules"] = toJSONArray(c.rules[:remaining])
+				keptRules[c.path] = remaining
+				remaining = 0
+				continue
+			}
+			remaining -= len(c.rules)
+		}
+
+		results := jsonObjects(run["results"])
+		if len(droppedRules) > 0 {
+			kept := results[:0]
+			for _, result := range results {
+				if resultRefersToDroppedRule(result, runPath, components, droppedRules, keptRules) {
+					continue
+				}
+				kept = append(kept, result)
+			}
+			if n := len(results) - len(kept); n > 0 {
+				dropped = append(dropped, fmt.Sprintf("%v.results: dropped %d results of dropped rules", runPath, n))
+			}
+			results = kept
+		}
+		if len(results) > maxResultsPerRun {
+			dropped = append(dropped, fmt.Sprintf("%v.results: dropped the last %d of %d results", runPath, len(results)-maxResultsPerRun, len(results)))
+			results = results[:maxResultsPerRun]
+		}
+		if _, ok := run["results"]; ok {
+			run["results"] = toJSONArray(results)
+		}
+
+		for j, result := range results {
+			locations := jsonObjects(result["locations"])
+			if len(locations) > maxLocationsPerResult {
+				dropped = append(dropped, fmt.Sprintf("%v.results[%d].locations: dropped the last %d of %d locations", runPath, j, len(locations)-maxLocationsPerResult, len(locations)))
+				result["locations"] = toJSONArray(locations[:maxLocationsPerResult])
+			}
+		}
+	}
+	return dropped
+}
+
+// resultRefersToDroppedRule reports whether a result refers to a rule that truncateToLimits dropped,
+// either by its rule ID or, when it has none, by its index into the rules of a tool component.
+// The tool component is the driver unless the result's rule names one by index or name.
+func resultRefersToDroppedRule(result map[string]interface{}, runPath string, components []toolComponent, droppedRules map[string]bool, keptRules map[string]int) bool {
+	rule, _ := result["rule"].(map[string]interface{})
+	if id, ok := result["ruleId"].(string); ok {
+		return droppedRules[id]
+	}
+	if id, ok := rule["id"].(string); ok {
+		return droppedRules[id]
+	}
+
+	path := runPath + ".tool.driver"
+	if ref, ok := rule["toolComponent"].(map[string]interface{}); ok {
+		path = ""
+		if i, ok := jsonInt(ref["index"]); ok {
+			path = fmt.Sprintf("%v.tool.extensions[%d]", runPath, i)
+		} else if name, ok := ref["name"].(string); ok {
+			for _, c := range components {
+				if c.component["name"] == name {
+					path = c.path
+					break
+				}
+			}
+		}
+	}
+	kept, truncated := keptRules[path]
+	if !truncated {
+		return false
+	}
+	index, ok := jsonInt(result["ruleIndex"])
+	if !ok {
+		index, ok = jsonInt(rule["index"])
+	}
+	return ok && index >= kept
+}
+
+// jsonInt returns the value of a JSON number decoded by decodeSarifJSON as an integer.
+func jsonInt(v interface{}) (int, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	i, err := n.Int64()
+	return int(i), err == nil
+}
+
+// toolComponent is a tool component (driver or extension) of a run, with its JSON path.
+type toolComponent struct {
+	path      string
+	component map[string]interface{}
+	rules     []map[string]interface{}
+}
+
+// runToolComponents returns the driver and extensions of a run, in that order.
+func runToolComponents(run map[string]interface{}, runPath string) []toolComponent {
+	var components []toolComponent
+	tool, _ := run["tool"].(map[string]interface{})
+	if driver, ok := tool["driver"].(map[string]interface{}); ok {
+		components = append(components, toolComponent{path: runPath + ".tool.driver", component: driver, rules: jsonObjects(driver["rules"])})
+	}
+	for i, ext := range jsonObjects(tool["extensions"]) {
+		components = append(components, toolComponent{path: fmt.Sprintf("%v.tool.extensions[%d]", runPath, i), component: ext, rules: jsonObjects(ext["rules"])})
+	}
+	return components
+}
+
+// ruleTags returns the tags of a rule from its property bag.
+func ruleTags(rule map[string]interface{}) []interface{} {
+	props, _ := rule["properties"].(map[string]interface{})
+	tags, _ := props["tags"].([]interface{})
+	return tags
+}
+
+// toJSONArray converts a slice of objects back into a generic JSON array.
+func toJSONArray(objs []map[string]interface{}) []interface{} {
+	a := make([]interface{}, len(objs))
+	for i, o := range objs {
+		a[i] = o
+	}
+	return a
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testRules returns a JSON array of n rules with IDs prefix0, prefix1, ...
+func testRules(prefix string, n int) string {
+	rules := make([]string, n)
+	for i := range rules {
+		rules[i] = fmt.Sprintf(`{"id":"%v%d"}`, prefix, i)
+	}
+	return "[" + strings.Join(rules, ",") + "]"
+}
+
+func TestTruncateToLimits(t *testing.T) {
+	last := maxRulesPerRun // Index of the first rule over the limit.
+	tests := []struct {
+		name        string
+		driver      string
+		extensions  string
+		results     string
+		wantRules   []int
+		wantResults []string
+		wantDropped []string
+	}{
+		{
+			name:   "driver rules dropped with their results by ruleId and ruleIndex",
+			driver: testRules("d", maxRulesPerRun+1),
+			results: fmt.Sprintf(`[
+				{"ruleId":"d0","message":{"text":"kept by id"}},
+				{"ruleId":"d%[1]d","message":{"text":"dropped by id"}},
+				{"ruleIndex":0,"message":{"text":"kept by index"}},
+				{"ruleIndex":%[1]d,"message":{"text":"dropped by index"}},
+				{"rule":{"id":"d%[1]d"},"message":{"text":"dropped by rule.id"}},
+				{"rule":{"index":%[1]d},"message":{"text":"dropped by rule.index"}}
+			]`, last),
+			wantRules:   []int{maxRulesPerRun},
+			wantResults: []string{"kept by id", "kept by index"},
+			wantDropped: []string{
+				fmt.Sprintf("$.runs[0].tool.driver.rules: dropped the last 1 of %d rules", maxRulesPerRun+1),
+				"$.runs[0].results: dropped 4 results of dropped rules",
+			},
+		},
+		{
+			name:       "extension rules dropped with their results by ruleId and ruleIndex",
+			driver:     testRules("d", maxRulesPerRun-1),
+			extensions: fmt.Sprintf(`[{"name":"pack","rules":%v}]`, testRules("e", 2)),
+			results: fmt.Sprintf(`[
+				{"ruleId":"e0","message":{"text":"kept by id"}},
+				{"ruleId":"e1","message":{"text":"dropped by id"}},
+				{"ruleIndex":0,"rule":{"toolComponent":{"index":0}},"message":{"text":"kept by index"}},
+				{"ruleIndex":1,"rule":{"toolComponent":{"index":0}},"message":{"text":"dropped by index"}},
+				{"ruleIndex":1,"rule":{"toolComponent":{"name":"pack"}},"message":{"text":"dropped by component name"}},
+				{"ruleIndex":%d,"message":{"text":"kept driver rule"}}
+			]`, maxRulesPerRun-2),
+			wantRules:   []int{maxRulesPerRun - 1, 1},
+			wantResults: []string{"kept by id", "kept by index", "kept driver rule"},
+			wantDropped: []string{
+				"$.runs[0].tool.extensions[0].rules: dropped the last 1 of 2 rules",
+				"$.runs[0].results: dropped 3 results of dropped rules",
+			},
+		},
+		{
+			name:        "within the limits",
+			driver:      testRules("d", 2),
+			results:     `[{"ruleId":"d1","message":{"text":"kept"}},{"ruleIndex":1,"message":{"text":"kept by index"}}]`,
+			wantRules:   []int{2},
+			wantResults: []string{"kept", "kept by index"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extensions := ""
+			if tt.extensions != "" {
+				extensions = `,"extensions":` + tt.extensions
+			}
+			doc, err := decodeSarifJSON([]byte(fmt.Sprintf(`{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"t","rules":%v}%v},"results":%v}]}`,
+				tt.driver, extensions, tt.results)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dropped := truncateToLimits(doc)
+			if !slices.Equal(dropped, tt.wantDropped) {
+				t.Errorf("dropped = %q, want %q", dropped, tt.wantDropped)
+			}
+
+			run := jsonObjects(doc["runs"])[0]
+			var gotRules []int
+			for _, c := range runToolComponents(run, "$.runs[0]") {
+				gotRules = append(gotRules, len(c.rules))
+			}
+			if !slices.Equal(gotRules, tt.wantRules) {
+				t.Errorf("rules per tool component = %v, want %v", gotRules, tt.wantRules)
+			}
+
+			var gotResults []string
+			for _, result := range jsonObjects(run["results"]) {
+				gotResults = append(gotResults, result["message"].(map[string]interface{})["text"].(string))
+			}
+			if !slices.Equal(gotResults, tt.wantResults) {
+				t.Errorf("results = %q, want %q", gotResults, tt.wantResults)
+			}
+			if violations := checkLimits(doc); len(violations) > 0 {
+				t.Errorf("still over the limits: %v", violations)
+			}
+		})
+	}
+}
@@ -22,17 +22,14 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
-	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/cli/go-gh/v2/pkg/api"
-	"github.com/cli/go-gh/v2/pkg/jsonpretty"
 	"github.com/cli/go-gh/v2/pkg/tableprinter"
 	"github.com/cli/go-gh/v2/pkg/term"
 	"github.com/spf13/cobra"
@@ -46,8 +43,14 @@ var pageFlag int
 var limitFlag int
 var sortFlag string
 
+var paginateFlag bool
+var groupBySetFlag bool
+
 const defaultLimit = 15
 
+// The maximum page size allowed by the API.
+const maxPerPage = 100
+
 type Analysis struct {
 	Ref          string `json:"ref"`
 	CommitSha    string `json:"commit_sha"`
@@ -70,11 +73,201 @@ type Analysis struct {
 	Warning   string `json:"warning"`
 }
 
+// Client-side filters, applied to every page of analyses.
+var sinceFlag string
+var untilFlag string
+var categoryFilterFlag string
+var shaFlag string
+var withErrorsFlag bool
+var withWarningsFlag bool
+var deletableFlag bool
+
+// analysisFilter selects analyses on fields the API can't filter by.
+type analysisFilter struct {
+	since        time.Time
+	until        time.Time
+	category     string
+	sha          string
+	withErrors   bool
+	withWarnings bool
+	deletable    bool
+}
+
+// newAnalysisFilter builds an analysisFilter from the list flags.
+func newAnalysisFilter() (analysisFilter, error) {
+	f := analysisFilter{
+		category:     categoryFilterFlag,
+		sha:          strings.ToLower(shaFlag),
+		withErrors:   withErrorsFlag,
+		withWarnings: withWarningsFlag,
+		deletable:    deletableFlag,
+	}
+	var err error
+	if sinceFlag != "" {
+		if f.since, _, err = parseDateFlag(sinceFlag); err != nil {
+			return f, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if untilFlag != "" {
+		var dateOnly bool
+		if f.until, dateOnly, err = parseDateFlag(untilFlag); err != nil {
+			return f, fmt.Errorf("invalid --until: %w", err)
+		}
+		// A date on its own includes the whole of that day.
+		if dateOnly {
+			f.until = f.until.AddDate(0, 0, 1)
+		}
+	}
+	return f, nil
+}
+
+// parseDateFlag parses a date (2006-01-02) or timestamp (2006-01-02T15:04:05Z), reporting which it was.
+func parseDateFlag(s string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return t, false, fmt.Errorf("%q is not a date (2006-01-02) or timestamp (2006-01-02T15:04:05Z)", s)
+	}
+	return t, true, nil
+}
+
+// match reports whether an analysis passes every filter that is set.
+func (f analysisFilter) match(a Analysis) bool {
+	if !f.since.IsZero() || !f.until.IsZero() {
+		created, err := time.Parse(time.RFC3339, a.CreatedAt)
+		if err != nil {
+			return false
+		}
+		if !f.since.IsZero() && created.Before(f.since) {
+			return false
+		}
+		if !f.until.IsZero() && !created.Before(f.until) {
+			return false
+		}
+	}
+	if f.category != "" && a.Category != f.category && a.Category != categoryAutomationID(f.category) {
+		return false
+	}
+	if f.sha != "" && !strings.HasPrefix(strings.ToLower(a.CommitSha), f.sha) {
+		return false
+	}
+	if f.withErrors && a.Error == "" {
+		return false
+	}
+	if f.withWarnings && a.Warning == "" {
+		return false
+	}
+	if f.deletable && !a.Deletable {
+		return false
+	}
+	return true
+}
+
+// analysisSet is a chain of analyses that GitHub treats as one: they share a ref, analysis key,
+// environment, category and tool. Only the newest analysis of a set can be deleted.
+type analysisSet struct {
+	Ref          string   `json:"ref"`
+	AnalysisKey  string   `json:"analysis_key"`
+	Environment  string   `json:"environment"`
+	Category     string   `json:"category"`
+	Tool         string   `json:"tool"`
+	Newest       Analysis `json:"newest"`
+	Analyses     int      `json:"analyses"`
+	ResultsCount int      `json:"results_count"`
+	Deletable    bool     `json:"deletable"`
+}
+
+// groupAnalysisSets groups analyses into their sets, newest set first.
+func groupAnalysisSets(analyses []Analysis) []*analysisSet {
+	var sets []*analysisSet
+	byKey := map[[5]string]*analysisSet{}
+	for _, a := range analyses {
+		key := [5]string{a.Ref, a.AnalysisKey, a.Environment, a.Category, a.Tool.Name}
+		set, ok := byKey[key]
+		if !ok {
+			set = &analysisSet{
+				Ref:         a.Ref,
+				AnalysisKey: a.AnalysisKey,
+				Environment: a.Environment,
+				Category:    a.Category,
+				Tool:        a.Tool.Name,
+				Newest:      a,
+			}
+			byKey[key] = set
+			sets = append(sets, set)
+		}
+		set.Analyses++
+		set.ResultsCount += a.ResultsCount
+		set.Deletable = set.Deletable || a.Deletable
+		if analysisCreatedAt(a).After(analysisCreatedAt(set.Newest)) {
+			set.Newest = a
+		}
+	}
+	sort.SliceStable(sets, func(i, j int) bool {
+		return analysisCreatedAt(sets[i].Newest).After(analysisCreatedAt(sets[j].Newest))
+	})
+	return sets
+}
+
+// analysisCreatedAt parses the creation time of an analysis, returning the zero time if it can't.
+func analysisCreatedAt(a Analysis) time.Time {
+	t, _ := time.Parse(time.RFC3339, a.CreatedAt)
+	return t
+}
+
+// printAnalysisSets prints a table with one row per analysis set.
+func printAnalysisSets(sets []*analysisSet) error {
+	cyan := func(s string) string {
+		return "\u001B[96m" + s + "\u001B[39m"
+	}
+
+	red := func(s string) string {
+		return "\u001B[91m" + s + "\u001B[39m"
+	}
+
+	terminal := term.FromEnv()
+	termWidth, _, _ := terminal.Size()
+	t := tableprinter.New(terminal.Out(), terminal.IsTerminalOutput(), termWidth)
+
+	if terminal.IsTerminalOutput() {
+		fmt.Printf("Showing %d analysis sets\n\n", len(sets))
+	}
+
+	t.AddHeader([]string{"Newest ID", "Created At", "Ref", "Tool", "Category", "Analyses", "Total Results", "Deleteable"})
+	for _, set := range sets {
+		deletable := red
+		if set.Deletable {
+			deletable = cyan
+		}
+
+		t.AddField(strconv.Itoa(set.Newest.ID), tableprinter.WithTruncate(nil))
+		t.AddField(set.Newest.CreatedAt)
+		t.AddField(set.Ref)
+		t.AddField(fmt.Sprintf(`%v@%v`, set.Tool, set.Newest.Tool.Version))
+		t.AddField(set.Category)
+		t.AddField(strconv.Itoa(set.Analyses))
+		t.AddField(strconv.Itoa(set.ResultsCount))
+		t.AddField(strconv.FormatBool(set.Deletable), tableprinter.WithColor(deletable))
+		t.EndRow()
+	}
+	return t.Render()
+}
+
 // listCmd represents the list command
 var listCmd = &cobra.Command{
 	Use:   "list [flags]",
 	Short: "List GitHub Code Scanning analyses for a repository",
-	Long:  fmt.Sprintf(`List analyses for a repository. By default, the most recent %v analyses are listed.`, defaultLimit),
+	Long: fmt.Sprintf(`List analyses for a repository. By default, the most recent %v analyses are listed.
+
+	Use --paginate to fetch every page of analyses. --limit then caps the total number of analyses listed.
+
+	--since, --until, --category, --sha, --with-errors, --with-warnings and --deletable filter the analyses
+	after they are fetched, so combine them with --paginate to search the whole history.
+
+	Use --group-by-set to show one row per analysis set, i.e. the analyses that share a ref, analysis key,
+	environment, category and tool. Sets are built from the analyses fetched, so use --paginate for complete sets.`, defaultLimit),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Setup Repository
 		repo, err := GetRepository()
@@ -89,12 +282,33 @@ var listCmd = &cobra.Command{
 			fmt.Println(err)
 		}
 
-		params := url.Values{}
-		if limitFlag != defaultLimit {
-			params.Add("per_page", fmt.Sprintf("%v", limitFlag))
-		} else {
-			params.Add("per_page", fmt.Sprintf("%v", defaultLimit))
+		fields := jsonFields(Analysis{})
+		if groupBySetFlag {
+			fields = jsonFields(analysisSet{})
+		}
+		if err := checkJSONFields(fields); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		filter, err := newAnalysisFilter()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		// When paginating, --limit caps the total number of analyses rather than the page size.
+		limit := limitFlag
+		perPage := limitFlag
+		if paginateFlag {
+			perPage = maxPerPage
+			if !cmd.Flags().Changed("limit") {
+				limit = 0
+			}
 		}
+
+		params := url.Values{}
+		params.Add("per_page", fmt.Sprintf("%v", perPage))
 		if refFlag != "" {
 			params.Add("ref", refFlag)
 		}
@@ -109,42 +323,70 @@ var listCmd = &cobra.Command{
 		}
 
 		u.RawQuery = params.Encode()
-		var opts api.ClientOptions
-		if repo.Host != "" {
-			opts.Host = repo.Host
-		}
-		client, err := api.NewRESTClient(opts)
+		client, err := newRESTClient(repo, nil)
 		if err != nil {
 			fmt.Println(err)
 			return
 		}
 
-		response, err := client.Request(http.MethodGet, u.String(), nil)
+		var rawAnalyses []json.RawMessage
+		var bodyJSON []Analysis
+		err = fetchPages(client, u.String(), paginateFlag, func(body []byte) (bool, error) {
+			var page []json.RawMessage
+			if err := json.Unmarshal(body, &page); err != nil {
+				return false, err
+			}
+			for _, raw := range page {
+				var analysis Analysis
+				if err := json.Unmarshal(raw, &analysis); err != nil {
+					return false, err
+				}
+				if !filter.match(analysis) {
+					continue
+				}
+				rawAnalyses = append(rawAnalyses, raw)
+				bodyJSON = append(bodyJSON, analysis)
+				if limit > 0 && len(bodyJSON) >= limit {
+					return false, nil
+				}
+			}
+			return len(page) > 0, nil
+		})
 		if err != nil {
 			fmt.Println(err)
 			return
 		}
 
-		bodyBytes, err := io.ReadAll(response.Body)
-		bodyString := string(bodyBytes)
-
-		if err != nil {
-			fmt.Println(err)
+		if groupBySetFlag {
+			sets := groupAnalysisSets(bodyJSON)
+			if jsonOutput() {
+				b, err := json.Marshal(sets)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				if err = printJSON(b); err != nil {
+					fmt.Println(err)
+				}
+				return
+			}
+			if err := printAnalysisSets(sets); err != nil {
+				fmt.Println(err)
+			}
 			return
 		}
 
-		if jsonFlag {
-			writer := os.Stdout
-			defer writer.Close()
-
+		if jsonOutput() {
+			if rawAnalyses == nil {
+				rawAnalyses = []json.RawMessage{}
+			}
+			b, err := json.Marshal(rawAnalyses)
 			if err != nil {
 				fmt.Println(err)
 				return
 			}
 
-			reader := bytes.NewBufferString(bodyString)
-
-			err = jsonpretty.Format(writer, reader, "\t", true)
+			err = printJSON(b)
 			if err != nil {
 				fmt.Println(err)
 				return
@@ -164,19 +406,14 @@ var listCmd = &cobra.Command{
 			return "\u001B[93m" + s + "\u001B[39m"
 		}
 
-		var bodyJSON []Analysis
-		err = json.Unmarshal(bodyBytes, &bodyJSON)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-
 		// Table Print
 		terminal := term.FromEnv()
 		termWidth, _, _ := terminal.Size()
 		t := tableprinter.New(terminal.Out(), terminal.IsTerminalOutput(), termWidth)
 
-		if terminal.IsTerminalOutput() {
+		if terminal.IsTerminalOutput() && paginateFlag {
+			fmt.Printf("Showing %d analyses\n\n", len(bodyJSON))
+		} else if terminal.IsTerminalOutput() {
 			// Unfortunately, the API doesn't return the total pages of analyses -
 			// https://docs.github.com/en/rest/code-scanning/code-scanning?apiVersion=2022-11-28#list-code-scanning-analyses-for-a-repository
 			fmt.Printf("Showing %d analyses on page %d/?\n\n", len(bodyJSON), pageFlag)
@@ -219,10 +456,20 @@ var listCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(listCmd)
+	setJSONFields(listCmd, Analysis{})
 	listCmd.Flags().StringVarP(&refFlag, "ref", "r", "", " The ref for a branch can be formatted either as refs/heads/<branch name> or simply <branch name>. To reference a pull request use refs/pull/<number>/merge.")
 	listCmd.Flags().StringVarP(&toolNameFlag, "tool", "t", "", "Tool name")
 	listCmd.Flags().IntVarP(&pageFlag, "page", "p", 1, "Page number of analyses to return")
-	listCmd.Flags().IntVarP(&limitFlag, "limit", "L", defaultLimit, "Number of analyses to return per page (default 30, max 100)")
+	listCmd.Flags().IntVarP(&limitFlag, "limit", "L", defaultLimit, "Number of analyses to list: the page size (max 100), or the total with --paginate")
 	listCmd.Flags().StringVarP(&sortFlag, "sort", "s", "", "The property by which to sort the results.")
-	// listCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output JSON instead of text (includes additional fields)")
+	listCmd.Flags().BoolVar(&paginateFlag, "paginate", false, "Fetch all pages of analyses (--limit becomes the total number of analyses)")
+	listCmd.Flags().BoolVar(&paginateFlag, "all", false, "Alias for --paginate")
+	listCmd.Flags().StringVar(&sinceFlag, "since", "", "Only list analyses created on or after this date (2006-01-02) or time (2006-01-02T15:04:05Z)")
+	listCmd.Flags().StringVar(&untilFlag, "until", "", "Only list analyses created on or before this date (2006-01-02) or before this time (2006-01-02T15:04:05Z)")
+	listCmd.Flags().StringVarP(&categoryFilterFlag, "category", "c", "", "Only list analyses with this category")
+	listCmd.Flags().StringVar(&shaFlag, "sha", "", "Only list analyses of commits starting with this SHA")
+	listCmd.Flags().BoolVar(&withErrorsFlag, "with-errors", false, "Only list analyses with errors")
+	listCmd.Flags().BoolVar(&withWarningsFlag, "with-warnings", false, "Only list analyses with warnings")
+	listCmd.Flags().BoolVar(&deletableFlag, "deletable", false, "Only list deletable analyses")
+	listCmd.Flags().BoolVar(&groupBySetFlag, "group-by-set", false, "Group analyses into sets (ref, analysis key, environment, category and tool) and show one row per set")
 }
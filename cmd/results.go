@@ -0,0 +1,677 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+// resultRow is a result together with the run it belongs to.
+type resultRow struct {
+	run    *sarif.Run
+	result *sarif.Result
+	// index is the position of the result in the SARIF log, counting from 1 across all runs.
+	index int
+}
+
+// collectResults returns the results of every run in a SARIF log, in order.
+func collectResults(r *sarif.Report) []resultRow {
+	var rows []resultRow
+	for _, run := range r.Runs {
+		for _, result := range run.Results {
+			rows = append(rows, resultRow{run: run, result: result, index: len(rows) + 1})
+		}
+	}
+	return rows
+}
+
+// ruleID returns the ID of the rule that produced the result.
+func (row resultRow) ruleID() string {
+	if row.result.RuleID != nil {
+		return *row.result.RuleID
+	}
+	if row.result.Rule != nil && row.result.Rule.Id != nil {
+		return *row.result.Rule.Id
+	}
+	if rule := row.rule(); rule != nil {
+		return rule.ID
+	}
+	return ""
+}
+
+// rule returns the rule that produced the result.
+func (row resultRow) rule() *sarif.ReportingDescriptor {
+	return lookupRule(row.run, row.result.Rule, row.result.RuleIndex, row.result.RuleID)
+}
+
+// lookupRule finds a rule following SARIF's rules for reportingDescriptorReference:
+// the rule's toolComponent picks the driver or an extension, then the rule is found by
+// index, or else by ID. Without a toolComponent, IDs are also looked up in the extensions.
+func lookupRule(run *sarif.Run, ref *sarif.ReportingDescriptorReference, index *uint, id *string) *sarif.ReportingDescriptor {
+	var component *sarif.ToolComponent
+	explicit := false
+	if ref != nil {
+		if ref.ToolComponent != nil {
+			component = lookupToolComponent(run, ref.ToolComponent)
+			explicit = true
+		}
+		if ref.Index != nil {
+			index = ref.Index
+		}
+		if ref.Id != nil {
+			id = ref.Id
+		}
+	}
+	if !explicit {
+		component = run.Tool.Driver
+	}
+	if component == nil {
+		return nil
+	}
+	if index != nil && int(*index) < len(component.Rules) {
+		return component.Rules[*index]
+	}
+	if id == nil {
+		return nil
+	}
+
+	components := []*sarif.ToolComponent{component}
+	if !explicit {
+		components = append(components, run.Tool.Extensions...)
+	}
+	for _, c := range components {
+		if c == nil {
+			continue
+		}
+		for _, rule := range c.Rules {
+			if rule != nil && rule.ID == *id {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// lookupToolComponent finds the driver or extension a toolComponentReference refers to.
+func lookupToolComponent(run *sarif.Run, ref *sarif.ToolComponentReference) *sarif.ToolComponent {
+	if ref.Index != nil {
+		if int(*ref.Index) < len(run.Tool.Extensions) {
+			return run.Tool.Extensions[*ref.Index]
+		}
+		return nil
+	}
+	components := append([]*sarif.ToolComponent{run.Tool.Driver}, run.Tool.Extensions...)
+	for _, c := range components {
+		if c == nil {
+			continue
+		}
+		if ref.Guid != nil && c.GUID != nil && strings.EqualFold(*c.GUID, *ref.Guid) {
+			return c
+		}
+		if ref.Guid == nil && ref.Name != nil && c.Name == *ref.Name {
+			return c
+		}
+	}
+	// A reference with neither an index nor a match refers to the driver.
+	if ref.Guid == nil && ref.Name == nil {
+		return run.Tool.Driver
+	}
+	return nil
+}
+
+// message returns the text of the result's message, falling back to its markdown.
+func (row resultRow) message() string {
+	return messageText(&row.result.Message)
+}
+
+// messageText returns the text of a message, falling back to its markdown.
+func messageText(m *sarif.Message) string {
+	if m == nil {
+		return ""
+	}
+	if m.Text != nil {
+		return *m.Text
+	}
+	if m.Markdown != nil {
+		return *m.Markdown
+	}
+	return ""
+}
+
+// level returns the level of the result.
+func (row resultRow) level() string {
+	if row.result.Level != nil {
+		return *row.result.Level
+	}
+	return ""
+}
+
+// effectiveLevel returns the level of the result following SARIF 2.1.0: results whose kind
+// isn't "fail" have level none, otherwise the result's own level applies, then a
+// ruleConfigurationOverride of its rule, then the rule's defaultConfiguration, and then warning.
+func (row resultRow) effectiveLevel() string {
+	if row.result.Kind != nil && *row.result.Kind != "" && *row.result.Kind != "fail" {
+		return "none"
+	}
+	if l := row.level(); l != "" {
+		return l
+	}
+	rule := row.rule()
+	if rule == nil {
+		return "warning"
+	}
+	for _, inv := range row.run.Invocations {
+		if inv == nil {
+			continue
+		}
+		for _, o := range inv.RuleConfigurationOverrides {
+			if o == nil || o.Descriptor == nil || o.Configuration == nil || o.Configuration.Level == "" {
+				continue
+			}
+			if lookupRule(row.run, o.Descriptor, nil, nil) == rule {
+				return o.Configuration.Level
+			}
+		}
+	}
+	if rule.DefaultConfiguration != nil && rule.DefaultConfiguration.Level != "" {
+		return rule.DefaultConfiguration.Level
+	}
+	return "warning"
+}
+
+// severity returns the severity GitHub shows for the result: critical, high, medium or low
+// from the security-severity score of security results, and the level for other results.
+func (row resultRow) severity() string {
+	if s, err := strconv.ParseFloat(row.securitySeverity(), 64); err == nil {
+		switch {
+		case s >= 9:
+			return "critical"
+		case s >= 7:
+			return "high"
+		case s >= 4:
+			return "medium"
+		case s > 0:
+			return "low"
+		}
+	}
+	return row.effectiveLevel()
+}
+
+// tags returns the tags of the result and of its rule.
+func (row resultRow) tags() []string {
+	var tags []string
+	bags := []sarif.Properties{row.result.Properties}
+	if rule := row.rule(); rule != nil {
+		bags = append(bags, rule.Properties)
+	}
+	for _, props := range bags {
+		if t, ok := props["tags"].([]interface{}); ok {
+			for _, tag := range t {
+				if s, ok := tag.(string); ok {
+					tags = append(tags, s)
+				}
+			}
+		}
+	}
+	return tags
+}
+
+// alertNumber returns the number of the GitHub alert the result belongs to, if known.
+func (row resultRow) alertNumber() string {
+	if n, ok := row.result.Properties["github/alertNumber"]; ok {
+		return fmt.Sprintf("%v", n)
+	}
+	return ""
+}
+
+// primaryLocation returns the physical location of the result's first location.
+func (row resultRow) primaryLocation() *sarif.PhysicalLocation {
+	if len(row.result.Locations) == 0 || row.result.Locations[0] == nil {
+		return nil
+	}
+	return row.result.Locations[0].PhysicalLocation
+}
+
+// file returns the path of the result's primary location.
+func (row resultRow) file() string {
+	loc := row.primaryLocation()
+	if loc == nil {
+		return ""
+	}
+	return artifactPath(row.run, loc.ArtifactLocation)
+}
+
+// location returns the result's primary location as path:startLine:startColumn.
+func (row resultRow) location() string {
+	return formatLocation(row.run, row.primaryLocation())
+}
+
+// allLocations returns every location of the result as path:startLine:startColumn.
+func (row resultRow) allLocations() []string {
+	var locations []string
+	for _, loc := range row.result.Locations {
+		if loc == nil {
+			continue
+		}
+		if l := formatLocation(row.run, loc.PhysicalLocation); l != "" {
+			locations = append(locations, l)
+		}
+	}
+	return locations
+}
+
+// formatLocation formats a physical location as path:startLine:startColumn, leaving out what it doesn't have.
+func formatLocation(run *sarif.Run, loc *sarif.PhysicalLocation) string {
+	if loc == nil {
+		return ""
+	}
+	l := artifactPath(run, loc.ArtifactLocation)
+	if loc.Region != nil && loc.Region.StartLine != nil {
+		l += fmt.Sprintf(":%d", *loc.Region.StartLine)
+		if loc.Region.StartColumn != nil {
+			l += fmt.Sprintf(":%d", *loc.Region.StartColumn)
+		}
+	}
+	return l
+}
+
+// artifactPath returns the path of an artifact. Absolute URIs are made relative to
+// the run's originalUriBaseIds where one of them contains the artifact, and URIs
+// relative to a uriBaseId are left as they are.
+func artifactPath(run *sarif.Run, loc *sarif.ArtifactLocation) string {
+	if loc == nil {
+		return ""
+	}
+	// A location may refer to an entry of run.artifacts instead of having its own URI.
+	if loc.URI == nil && loc.Index != nil && int(*loc.Index) < len(run.Artifacts) && run.Artifacts[*loc.Index] != nil {
+		loc = run.Artifacts[*loc.Index].Location
+		if loc == nil {
+			return ""
+		}
+	}
+	if loc.URI == nil {
+		return ""
+	}
+	uri := *loc.URI
+	if loc.URIBaseId != nil {
+		return uri
+	}
+
+	// Strip the longest base URI that the artifact is under.
+	base := ""
+	for id := range run.OriginalUriBaseIDs {
+		b := resolveBaseURI(run, id, 0)
+		if b != "" && strings.HasPrefix(uri, b) && len(b) > len(base) {
+			base = b
+		}
+	}
+	if base != "" {
+		return strings.TrimPrefix(uri, base)
+	}
+	return uri
+}
+
+// resolveBaseURI returns the absolute URI of an originalUriBaseIds entry, following
+// entries that are themselves relative to another base. It returns "" if it can't be resolved.
+func resolveBaseURI(run *sarif.Run, id string, depth int) string {
+	base, ok := run.OriginalUriBaseIDs[id]
+	if !ok || base == nil || base.URI == nil || depth > len(run.OriginalUriBaseIDs) {
+		return ""
+	}
+	uri := *base.URI
+	if uri != "" && !strings.HasSuffix(uri, "/") {
+		uri += "/"
+	}
+	if base.URIBaseId == nil {
+		if !strings.Contains(uri, ":") {
+			return ""
+		}
+		return uri
+	}
+	parent := resolveBaseURI(run, *base.URIBaseId, depth+1)
+	if parent == "" {
+		return ""
+	}
+	return parent + uri
+}
+
+// startLine returns the first line of the result's primary location.
+func (row resultRow) startLine() string {
+	loc := row.primaryLocation()
+	if loc == nil || loc.Region == nil || loc.Region.StartLine == nil {
+		return ""
+	}
+	return strconv.Itoa(*loc.Region.StartLine)
+}
+
+// endLine returns the last line of the result's primary location, which defaults to its first line.
+func (row resultRow) endLine() string {
+	loc := row.primaryLocation()
+	if loc == nil || loc.Region == nil {
+		return ""
+	}
+	if loc.Region.EndLine != nil {
+		return strconv.Itoa(*loc.Region.EndLine)
+	}
+	return row.startLine()
+}
+
+// ruleName returns the name of the rule that produced the result.
+func (row resultRow) ruleName() string {
+	if rule := row.rule(); rule != nil && rule.Name != nil {
+		return *rule.Name
+	}
+	return ""
+}
+
+// tool returns the name of the tool that produced the result.
+func (row resultRow) tool() string {
+	if row.run.Tool.Driver == nil {
+		return ""
+	}
+	return row.run.Tool.Driver.Name
+}
+
+// category returns the category (runAutomationDetails.id) of the result's run.
+func (row resultRow) category() string {
+	if row.run.AutomationDetails == nil || row.run.AutomationDetails.ID == nil {
+		return ""
+	}
+	return *row.run.AutomationDetails.ID
+}
+
+// fingerprint returns the result's primaryLocationLineHash, which GitHub uses to track alerts,
+// or its first other fingerprint if it has none.
+func (row resultRow) fingerprint() string {
+	if fp, ok := row.result.PartialFingerprints["primaryLocationLineHash"]; ok {
+		return fmt.Sprintf("%v", fp)
+	}
+	for _, fps := range []map[string]interface{}{row.result.PartialFingerprints, row.result.Fingerprints} {
+		keys := make([]string, 0, len(fps))
+		for k := range fps {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if len(keys) > 0 {
+			return fmt.Sprintf("%v", fps[keys[0]])
+		}
+	}
+	return ""
+}
+
+// securitySeverity returns the security-severity score of the result's rule, or of the result itself.
+func (row resultRow) securitySeverity() string {
+	if s, ok := row.result.Properties["security-severity"]; ok {
+		return fmt.Sprintf("%v", s)
+	}
+	if rule := row.rule(); rule != nil {
+		if s, ok := rule.Properties["security-severity"]; ok {
+			return fmt.Sprintf("%v", s)
+		}
+	}
+	return ""
+}
+
+// locationColumn returns the result's primary location, or all of its locations with --all-locations.
+func (row resultRow) locationColumn() string {
+	if allLocationsFlag {
+		return strings.Join(row.allLocations(), "; ")
+	}
+	return row.location()
+}
+
+// resultColumn is a column that can be chosen with --columns.
+type resultColumn struct {
+	name   string
+	header string
+	value  func(resultRow) string
+}
+
+// resultColumns are the columns available to CSV and TSV output, in their default order.
+var resultColumns = []resultColumn{
+	{"index", "#", func(row resultRow) string { return strconv.Itoa(row.index) }},
+	{"rule", "Rule ID", resultRow.ruleID},
+	{"message", "Description", resultRow.message},
+	{"alert", "Alert Number", resultRow.alertNumber},
+	{"level", "Level", resultRow.effectiveLevel},
+	{"severity", "Severity", resultRow.severity},
+	{"file", "File", resultRow.file},
+	{"location", "Location", resultRow.locationColumn},
+	{"start-line", "Start Line", resultRow.startLine},
+	{"end-line", "End Line", resultRow.endLine},
+	{"rule-name", "Rule Name", resultRow.ruleName},
+	{"tool", "Tool", resultRow.tool},
+	{"category", "Category", resultRow.category},
+	{"fingerprint", "Fingerprint", resultRow.fingerprint},
+	{"security-severity", "Security Severity", resultRow.securitySeverity},
+}
+
+// defaultResultColumns are the columns output when --columns isn't given.
+var defaultResultColumns = []string{"rule", "message", "alert", "level", "severity"}
+
+// resultColumnNames returns the names of every available column.
+func resultColumnNames() []string {
+	names := make([]string, len(resultColumns))
+	for i, c := range resultColumns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// selectResultColumns looks up columns by name.
+func selectResultColumns(names []string) ([]resultColumn, error) {
+	var columns []resultColumn
+	for _, name := range names {
+		found := false
+		for _, c := range resultColumns {
+			if c.name == name {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q, available columns: %v", name, strings.Join(resultColumnNames(), ", "))
+		}
+	}
+	return columns, nil
+}
+
+// writeResultsCSV writes results as CSV (or TSV if comma is a tab) with a single header row.
+func writeResultsCSV(w io.Writer, rows []resultRow, columns []resultColumn, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+
+	record := make([]string, len(columns))
+	for i, c := range columns {
+		record[i] = c.header
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		for i, c := range columns {
+			record[i] = c.value(row)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// sarifLevels are the levels a SARIF result can have.
+var sarifLevels = []string{"none", "note", "warning", "error"}
+
+// resultFilter selects results of a SARIF log.
+type resultFilter struct {
+	levels              []string
+	rule                *regexp.Regexp
+	path                *regexp.Regexp
+	tool                string
+	minSecuritySeverity float64
+	tags                []string
+}
+
+// newResultFilter builds a resultFilter from the view flags.
+func newResultFilter() (resultFilter, error) {
+	f := resultFilter{
+		tool:                toolFilterFlag,
+		minSecuritySeverity: minSecuritySeverityFlag,
+		tags:                tagFilterFlag,
+	}
+	for _, l := range levelFilterFlag {
+		l = strings.ToLower(l)
+		if !slices.Contains(sarifLevels, l) {
+			return f, fmt.Errorf("invalid --level %q: must be one of %v", l, strings.Join(sarifLevels, ", "))
+		}
+		f.levels = append(f.levels, l)
+	}
+	var err error
+	if ruleFilterFlag != "" {
+		if f.rule, err = ruleGlobRegexp(ruleFilterFlag); err != nil {
+			return f, fmt.Errorf("invalid --rule: %w", err)
+		}
+	}
+	if pathFilterFlag != "" {
+		if f.path, err = globRegexp(pathFilterFlag); err != nil {
+			return f, fmt.Errorf("invalid --path: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// match reports whether a result passes every filter.
+func (f resultFilter) match(row resultRow) bool {
+	if len(f.levels) > 0 && !slices.Contains(f.levels, row.effectiveLevel()) {
+		return false
+	}
+	if f.rule != nil && !f.rule.MatchString(row.ruleID()) {
+		return false
+	}
+	if f.path != nil && !slices.ContainsFunc(row.paths(), f.path.MatchString) {
+		return false
+	}
+	if f.tool != "" && !strings.EqualFold(row.tool(), f.tool) {
+		return false
+	}
+	if f.minSecuritySeverity > 0 {
+		s, err := strconv.ParseFloat(row.securitySeverity(), 64)
+		if err != nil || s < f.minSecuritySeverity {
+			return false
+		}
+	}
+	if len(f.tags) > 0 && !slices.ContainsFunc(row.tags(), func(t string) bool {
+		return slices.ContainsFunc(f.tags, func(want string) bool { return strings.EqualFold(t, want) })
+	}) {
+		return false
+	}
+	return true
+}
+
+// filter returns the rows that match the filter.
+func (f resultFilter) filter(rows []resultRow) []resultRow {
+	var matched []resultRow
+	for _, row := range rows {
+		if f.match(row) {
+			matched = append(matched, row)
+		}
+	}
+	return matched
+}
+
+// paths returns the paths of every location of the result.
+func (row resultRow) paths() []string {
+	var paths []string
+	for _, loc := range row.result.Locations {
+		if loc != nil && loc.PhysicalLocation != nil {
+			paths = append(paths, artifactPath(row.run, loc.PhysicalLocation.ArtifactLocation))
+		}
+	}
+	return paths
+}
+
+// globRegexp compiles a path glob to a regular expression. "*" and "?" don't match "/",
+// "**" matches across directories and "[...]" matches a character class.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	return compileGlob(glob, "[^/]")
+}
+
+// ruleGlobRegexp compiles a glob that matches rule IDs. Rule IDs aren't paths, so "*" and "?"
+// match "/" too, e.g. "*injection*" matches "go/sql-injection".
+func ruleGlobRegexp(glob string) (*regexp.Regexp, error) {
+	return compileGlob(glob, ".")
+}
+
+// compileGlob compiles a glob to a regular expression, where "*" and "?" match any of anyChar.
+func compileGlob(glob string, anyChar string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				// "**/" also matches no directories at all.
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+				continue
+			}
+			b.WriteString(anyChar + "*")
+		case '?':
+			b.WriteString(anyChar)
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class in %q", glob)
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			// Copy a whole UTF-8 character, not just its first byte.
+			r, size := utf8.DecodeRuneInString(glob[i:])
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			i += size - 1
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,124 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGlobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		compile func(string) (*regexp.Regexp, error)
+		glob    string
+		match   []string
+		noMatch []string
+	}{
+		{
+			name:    "path star stays within a directory",
+			compile: globRegexp,
+			glob:    "src/*.go",
+			match:   []string{"src/main.go", "src/.go"},
+			noMatch: []string{"src/a/main.go", "main.go", "src/main.gox"},
+		},
+		{
+			name:    "path double star matches any directories",
+			compile: globRegexp,
+			glob:    "**/vendor/**",
+			match:   []string{"vendor/a.go", "a/b/vendor/c/d.go"},
+			noMatch: []string{"vendored/a.go"},
+		},
+		{
+			name:    "path question mark and classes",
+			compile: globRegexp,
+			glob:    "v?/[ab]/[!x].go",
+			match:   []string{"v1/a/y.go", "v2/b/z.go"},
+			noMatch: []string{"v/a/y.go", "v1/c/y.go", "v1/a/x.go", "v//a/y.go"},
+		},
+		{
+			name:    "path regexp metacharacters are literal",
+			compile: globRegexp,
+			glob:    "a+b(c).go",
+			match:   []string{"a+b(c).go"},
+			noMatch: []string{"aab(c).go", "a+bc.go", "a+b(c)xgo"},
+		},
+		{
+			name:    "path non-ASCII characters",
+			compile: globRegexp,
+			glob:    "café/*.go",
+			match:   []string{"café/a.go"},
+			noMatch: []string{"cafe/a.go", "café/a/b.go"},
+		},
+		{
+			name:    "path question mark matches one non-ASCII character",
+			compile: globRegexp,
+			glob:    "caf?/日本?.go",
+			match:   []string{"café/日本語.go"},
+			noMatch: []string{"caf/日本語.go"},
+		},
+		{
+			name:    "rule star matches across slashes",
+			compile: ruleGlobRegexp,
+			glob:    "*injection*",
+			match:   []string{"go/sql-injection", "js/code-injection/extra"},
+			noMatch: []string{"go/xss"},
+		},
+		{
+			name:    "rule question mark matches a slash",
+			compile: ruleGlobRegexp,
+			glob:    "go?sql-injection",
+			match:   []string{"go/sql-injection"},
+			noMatch: []string{"go//sql-injection"},
+		},
+		{
+			name:    "rule non-ASCII characters",
+			compile: ruleGlobRegexp,
+			glob:    "règle/*",
+			match:   []string{"règle/a", "règle/a/b"},
+			noMatch: []string{"regle/a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := tt.compile(tt.glob)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.match {
+				if !re.MatchString(s) {
+					t.Errorf("%q (%v) doesn't match %q", tt.glob, re, s)
+				}
+			}
+			for _, s := range tt.noMatch {
+				if re.MatchString(s) {
+					t.Errorf("%q (%v) matches %q", tt.glob, re, s)
+				}
+			}
+		})
+	}
+
+	if _, err := globRegexp("a/[bc"); err == nil {
+		t.Error("unterminated character class compiled without an error")
+	}
+}
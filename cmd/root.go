@@ -46,11 +46,16 @@ func Execute() {
 }
 
 var repoFlag string
-var jsonFlag bool
+var jsonFlag []string
+var jqFlag string
+var templateFlag string
 
 func init() {
 	// ROOT FLAGS
 	rootCmd.PersistentFlags().StringVarP(&repoFlag, "repo", "R", "", "GitHub repository (format: owner/repo)")
-	rootCmd.PersistentFlags().BoolVarP(&jsonFlag, "json", "j", false, "Output JSON instead of text (includes additional fields)")
+	rootCmd.PersistentFlags().StringSliceVarP(&jsonFlag, "json", "j", nil, "Output JSON with the specified fields (run without a value to list them)")
+	rootCmd.PersistentFlags().StringVarP(&jqFlag, "jq", "q", "", "Filter JSON output using a jq expression")
+	rootCmd.SetFlagErrorFunc(jsonFlagError)
+	rootCmd.PersistentFlags().StringVar(&templateFlag, "template", "", "Format JSON output using a Go template; see \"gh help formatting\"")
 
 }
@@ -0,0 +1,120 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+// decodeSarifJSON decodes a SARIF log into generic JSON.
+// Used when the log needs to be modified, as re-encoding go-sarif types drops or nulls fields it does not model.
+func decodeSarifJSON(b []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// encodeSarifJSON encodes a SARIF log decoded with decodeSarifJSON.
+func encodeSarifJSON(doc map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(doc); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// jsonObjects returns the elements of a generic JSON array that are objects.
+func jsonObjects(v interface{}) []map[string]interface{} {
+	a, _ := v.([]interface{})
+	objs := make([]map[string]interface{}, 0, len(a))
+	for _, e := range a {
+		if o, ok := e.(map[string]interface{}); ok {
+			objs = append(objs, o)
+		}
+	}
+	return objs
+}
+
+// categoryAutomationID returns the runAutomationDetails.id to use for a category.
+// GitHub takes the category to be everything up to the final slash of the id, so one is appended if missing.
+func categoryAutomationID(category string) string {
+	if strings.HasSuffix(category, "/") {
+		return category
+	}
+	return category + "/"
+}
+
+// automationCategory returns the category part of a runAutomationDetails.id.
+func automationCategory(id string) string {
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		return id[:i+1]
+	}
+	return id
+}
+
+// runCategories returns the distinct categories set on the runs of a SARIF log, sorted.
+func runCategories(r *sarif.Report) []string {
+	seen := map[string]bool{}
+	var categories []string
+	for _, run := range r.Runs {
+		if run.AutomationDetails == nil || run.AutomationDetails.ID == nil || *run.AutomationDetails.ID == "" {
+			continue
+		}
+		c := automationCategory(*run.AutomationDetails.ID)
+		if !seen[c] {
+			seen[c] = true
+			categories = append(categories, c)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// setCategory sets runAutomationDetails.id on every run of the SARIF log, replacing any existing id.
+func setCategory(doc map[string]interface{}, category string) error {
+	runs, ok := doc["runs"].([]interface{})
+	if !ok {
+		return fmt.Errorf("SARIF log has no runs")
+	}
+	id := categoryAutomationID(category)
+	for _, run := range jsonObjects(runs) {
+		details, ok := run["automationDetails"].(map[string]interface{})
+		if !ok {
+			details = map[string]interface{}{}
+			run["automationDetails"] = details
+		}
+		details["id"] = id
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+/*
+Copyright © 2024 Kynan Ware
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+// Escape sequences used to highlight the flagged span of a snippet.
+const (
+	highlightOn  = "\033[1;4m"
+	highlightOff = "\033[0m"
+)
+
+// snippet is a run of source lines around a region.
+type snippet struct {
+	path      string
+	firstLine int
+	lines     []string
+	region    *sarif.Region
+}
+
+// loadSnippet returns the lines around a physical location, with up to context lines either side.
+// The lines come from the contextRegion snippet when it covers the region with whole lines, and from the file on disk otherwise.
+// Failing both, the region snippet is used. It holds only the text of the region, so all of it is highlighted.
+func loadSnippet(run *sarif.Run, loc *sarif.PhysicalLocation, context int) (*snippet, error) {
+	if loc == nil || loc.Region == nil || loc.Region.StartLine == nil {
+		return nil, fmt.Errorf("location has no line")
+	}
+	s := &snippet{path: artifactPath(run, loc.ArtifactLocation), region: loc.Region}
+	start, end := regionLines(loc.Region)
+
+	if r := loc.ContextRegion; r != nil && r.Snippet != nil && r.Snippet.Text != nil && r.StartLine != nil && (r.StartColumn == nil || *r.StartColumn == 1) {
+		if first, last := regionLines(r); first <= start && last >= end {
+			lines := strings.Split(strings.TrimSuffix(*r.Snippet.Text, "\n"), "\n")
+			s.lines = trimLines(lines, first, start-context, end+context)
+			s.firstLine = max(first, start-context)
+			return s, nil
+		}
+	}
+
+	b, err := os.ReadFile(localPath(run, loc.ArtifactLocation))
+	if err != nil {
+		if r := loc.Region; r.Snippet != nil && r.Snippet.Text != nil {
+			whole := *r
+			whole.StartColumn, whole.EndColumn = nil, nil
+			s.region = &whole
+			s.firstLine = start
+			s.lines = strings.Split(strings.TrimSuffix(strings.ReplaceAll(*r.Snippet.Text, "\r\n", "\n"), "\n"), "\n")
+			return s, nil
+		}
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+	if start > len(lines) {
+		return nil, fmt.Errorf("%v has %v lines, the result is on line %v", s.path, len(lines), start)
+	}
+	s.firstLine = max(1, start-context)
+	s.lines = trimLines(lines, 1, start-context, end+context)
+	return s, nil
+}
+
+// regionLines returns the first and last line of a region.
+func regionLines(r *sarif.Region) (int, int) {
+	start := *r.StartLine
+	end := start
+	if r.EndLine != nil && *r.EndLine > start {
+		end = *r.EndLine
+	}
+	return start, end
+}
+
+// trimLines returns the lines numbered from to to, where lines starts at line first.
+func trimLines(lines []string, first, from, to int) []string {
+	from = max(from-first, 0)
+	to = min(to-first+1, len(lines))
+	if from >= to {
+		return nil
+	}
+	return lines[from:to]
+}
+
+// localPath returns where an artifact is in the local checkout. Relative paths are looked up
+// in the current directory, then from the root of the git repository.
+func localPath(run *sarif.Run, loc *sarif.ArtifactLocation) string {
+	p := artifactPath(run, loc)
+	if u, err := url.Parse(p); err == nil && u.Scheme == "file" {
+		return filepath.FromSlash(u.Path)
+	}
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		p = unescaped
+	}
+	p = filepath.FromSlash(p)
+	if filepath.IsAbs(p) {
+		return p
+	}
+	if _, err := os.Stat(p); err != nil {
+		if root, err := gitOutput("rev-parse", "--show-toplevel"); err == nil {
+			return filepath.Join(root, p)
+		}
+	}
+	return p
+}
+
+// printSnippet writes a snippet with line numbers, marking the lines of the region and highlighting its span.
+// Without color the span is underlined with carets instead.
+func printSnippet(w io.Writer, s *snippet, color bool) error {
+	start, end := regionLines(s.region)
+	last := s.firstLine + len(s.lines) - 1
+	width := len(fmt.Sprint(last))
+
+	var h *highlighter
+	if color {
+		h = newHighlighter(s)
+	}
+	for i, line := range s.lines {
+		n := s.firstLine + i
+		marker := " "
+		from, to := -1, -1
+		if n >= start && n <= end {
+			marker = ">"
+			from, to = spanColumns(s.region, n, line)
+		}
+		fmt.Fprintf(w, "%v %*d | ", marker, width, n)
+		if h != nil {
+			if err := h.write(w, line, from, to); err != nil {
+				return err
+			}
+			fmt.Fprintln(w)
+			continue
+		}
+		fmt.Fprintln(w, line)
+		if from >= 0 && to > from {
+			r := []rune(line)
+			indent := strings.Map(func(c rune) rune {
+				if c == '\t' {
+					return c
+				}
+				return ' '
+			}, string(r[:from]))
+			fmt.Fprintf(w, "  %*s | %v%v\n", width, "", indent, strings.Repeat("^", to-from))
+		}
+	}
+	return nil
+}
+
+// spanColumns returns the rune offsets of the part of a line that's in a region.
+// SARIF columns are 1-based and the end column is exclusive.
+func spanColumns(r *sarif.Region, n int, line string) (int, int) {
+	length := len([]rune(line))
+	start, end := regionLines(r)
+	from, to := 0, length
+	if n == start && r.StartColumn != nil {
+		from = min(max(*r.StartColumn-1, 0), length)
+	}
+	if n == end && r.EndColumn != nil {
+		to = min(max(*r.EndColumn-1, from), length)
+	}
+	// Skip leading indentation when the whole line is flagged.
+	if from == 0 && (n != start || r.StartColumn == nil) {
+		from = length - len([]rune(strings.TrimLeft(line, " \t")))
+	}
+	return from, to
+}
+
+// highlighter syntax highlights source lines for the terminal.
+type highlighter struct {
+	lexer     chroma.Lexer
+	style     *chroma.Style
+	formatter chroma.Formatter
+}
+
+// newHighlighter picks a lexer for a snippet from its source language, file name or content.
+func newHighlighter(s *snippet) *highlighter {
+	var lexer chroma.Lexer
+	if s.region.SourceLanguage != nil {
+		lexer = lexers.Get(*s.region.SourceLanguage)
+	}
+	if lexer == nil {
+		lexer = lexers.Match(filepath.Base(s.path))
+	}
+	if lexer == nil {
+		lexer = lexers.Analyse(strings.Join(s.lines, "\n"))
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	return &highlighter{
+		lexer:     chroma.Coalesce(lexer),
+		style:     styles.Get("monokai"),
+		formatter: formatters.TTY256,
+	}
+}
+
+// write writes a highlighted line, also highlighting the runes from from to to.
+func (h *highlighter) write(w io.Writer, line string, from, to int) error {
+	tokens, err := chroma.Tokenise(h.lexer, nil, line)
+	if err != nil {
+		return err
+	}
+	pos := 0
+	for _, t := range tokens {
+		value := strings.TrimRight(t.Value, "\n")
+		runes := []rune(value)
+		// Split the token where the flagged span starts and ends.
+		cuts := []int{0}
+		for _, c := range []int{from - pos, to - pos} {
+			if c > 0 && c < len(runes) {
+				cuts = append(cuts, c)
+			}
+		}
+		cuts = append(cuts, len(runes))
+		for i := 0; i+1 < len(cuts); i++ {
+			if cuts[i] >= cuts[i+1] {
+				continue
+			}
+			piece := string(runes[cuts[i]:cuts[i+1]])
+			inSpan := pos+cuts[i] >= from && pos+cuts[i] < to
+			if err := h.writeToken(w, chroma.Token{Type: t.Type, Value: piece}, inSpan); err != nil {
+				return err
+			}
+		}
+		pos += len(runes)
+	}
+	return nil
+}
+
+// writeToken writes a token in its style color, highlighted if it's in the flagged span.
+func (h *highlighter) writeToken(w io.Writer, t chroma.Token, highlight bool) error {
+	if !highlight {
+		return h.formatter.Format(w, h.style, chroma.Literator(t))
+	}
+	// Format an empty token to get the color alone, so the highlight can be added after it.
+	var b bytes.Buffer
+	if err := h.formatter.Format(&b, h.style, chroma.Literator(chroma.Token{Type: t.Type})); err != nil {
+		return err
+	}
+	color := strings.TrimSuffix(b.String(), "\033[0m")
+	_, err := fmt.Fprint(w, color+highlightOn+t.Value+highlightOff)
+	return err
+}
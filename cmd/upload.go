@@ -26,22 +26,37 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/cli/go-gh/v2/pkg/term"
 	"github.com/owenrumney/go-sarif/v2/sarif"
 	"github.com/spf13/cobra"
 )
 
+// Request body of the SARIF upload API.
+// https://docs.github.com/en/rest/code-scanning/code-scanning?apiVersion=2022-11-28#upload-an-analysis-as-sarif-data
 type sarifUpload struct {
-	CommitSha string `json:"commit_sha"`
-	Ref       string `json:"ref"`
-	Sarif     string `json:"sarif"`
-	Validate  bool   `json:"validate"`
+	CommitSha   string `json:"commit_sha"`
+	Ref         string `json:"ref"`
+	Sarif       string `json:"sarif"`
+	CheckoutURI string `json:"checkout_uri,omitempty"`
+	StartedAt   string `json:"started_at,omitempty"`
+	ToolName    string `json:"tool_name,omitempty"`
+	Validate    bool   `json:"validate"`
 }
 
 type uploadedOK struct {
@@ -49,110 +64,678 @@ type uploadedOK struct {
 	UploadURL string `json:"url"`
 }
 
-// uploadCmd represents the upload command
-var uploadCmd = &cobra.Command{
-	Use:   "upload [flags] <commit_sha> <ref> <sarif_file>",
-	Short: "Upload a SARIF file to GitHub Code Scanning",
-	Long:  ``,
-	Args:  cobra.MinimumNArgs(3),
-	Run: func(cmd *cobra.Command, args []string) {
-		// Setup Repository
-		repo, err := GetRepository()
+// Represents the processing status of an uploaded SARIF file.
+// https://docs.github.com/en/rest/code-scanning/code-scanning?apiVersion=2022-11-28#get-information-about-a-sarif-upload
+type sarifStatus struct {
+	ProcessingStatus string   `json:"processing_status"`
+	AnalysesURL      string   `json:"analyses_url"`
+	Errors           []string `json:"errors"`
+}
+
+// uploadResult is the outcome of uploading a single SARIF file, as output with --json.
+// The processing status and analyses are only set when using --wait.
+type uploadResult struct {
+	uploadedOK
+	*sarifStatus
+	Analyses []Analysis `json:"analyses,omitempty"`
+}
+
+// Bounds for the backoff used while polling the processing status of an upload.
+const (
+	waitInitialDelay = 2 * time.Second
+	waitMaxDelay     = 30 * time.Second
+)
+
+// waitForProcessing polls the status of a SARIF upload until GitHub reports it as complete or failed.
+// The delay between requests doubles after each poll, up to waitMaxDelay.
+// Returns an error if the upload is still pending once the timeout has elapsed.
+func waitForProcessing(client *api.RESTClient, repo repository.Repository, id string, timeout time.Duration) (sarifStatus, error) {
+	var status sarifStatus
+	statusURL := fmt.Sprintf("repos/%v/%v/code-scanning/sarifs/%v", repo.Owner, repo.Name, id)
+	deadline := time.Now().Add(timeout)
+	delay := waitInitialDelay
+	for {
+		response, err := client.Request(http.MethodGet, statusURL, nil)
 		if err != nil {
-			fmt.Println(err)
-			return
+			// The upload may not be visible yet right after it was accepted, so keep polling on a 404.
+			var httpErr *api.HTTPError
+			if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+				return status, err
+			}
+		} else {
+			err = json.NewDecoder(response.Body).Decode(&status)
+			response.Body.Close()
+			if err != nil {
+				return status, err
+			}
+			if status.ProcessingStatus == "complete" || status.ProcessingStatus == "failed" {
+				return status, nil
+			}
 		}
 
-		// Read the SARIF file
-		sarifBytes, err := os.ReadFile(args[2])
+		if time.Now().Add(delay).After(deadline) {
+			return status, fmt.Errorf("timed out after %v waiting for SARIF %v to be processed", timeout, id)
+		}
+		time.Sleep(delay)
+		delay *= 2
+		if delay > waitMaxDelay {
+			delay = waitMaxDelay
+		}
+	}
+}
+
+// getUploadAnalyses returns the analyses that were created from a processed SARIF upload.
+func getUploadAnalyses(client *api.RESTClient, analysesURL string) ([]Analysis, error) {
+	var analyses []Analysis
+	if err := client.Get(analysesURL, &analyses); err != nil {
+		return nil, err
+	}
+	return analyses, nil
+}
+
+// resolveUploadArgs works out the commit SHA, ref and SARIF file from the positional arguments.
+// The SHA and ref are optional and are inferred from the environment when missing.
+// When only one of them is given, a 40 character hex string is taken to be the SHA.
+func resolveUploadArgs(args []string) (sha string, ref string, file string, err error) {
+	file = args[len(args)-1]
+	switch len(args) {
+	case 3:
+		sha, ref = args[0], args[1]
+	case 2:
+		if commitShaRE.MatchString(args[0]) {
+			sha = args[0]
+		} else {
+			ref = args[0]
+		}
+	}
+
+	if sha == "" {
+		if sha, err = inferCommitSha(); err != nil {
+			return "", "", "", err
+		}
+	}
+	if ref == "" {
+		if ref, err = inferRef(); err != nil {
+			return "", "", "", err
+		}
+	}
+
+	if err = validateCommitSha(sha); err != nil {
+		return "", "", "", err
+	}
+	if err = validateRef(ref); err != nil {
+		return "", "", "", err
+	}
+	return sha, ref, file, nil
+}
+
+// checkoutURI converts a local checkout path to the file URI expected by the upload API.
+// Values that are already URIs are returned unchanged.
+func checkoutURI(p string) (string, error) {
+	if strings.Contains(p, "://") {
+		return p, nil
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	abs = filepath.ToSlash(abs)
+	// Windows paths (C:/...) need a leading slash to form a valid file URI.
+	if !strings.HasPrefix(abs, "/") {
+		abs = "/" + abs
+	}
+	u := url.URL{Scheme: "file", Path: abs}
+	return u.String(), nil
+}
+
+// sarifExtensions are the file extensions picked up when uploading a directory of SARIF files.
+var sarifExtensions = []string{".sarif", ".sarif.json", ".sarif.gz"}
+
+// Maximum number of SARIF files uploaded at the same time.
+const uploadConcurrency = 4
+
+// expandSarifPaths resolves the SARIF file argument of upload into a list of files.
+// The argument may be a single file, stdin ("-"), a directory (searched recursively for SARIF files) or a glob.
+func expandSarifPaths(arg string) ([]string, error) {
+	if arg == stdinPath {
+		return []string{arg}, nil
+	}
+	if info, err := os.Stat(arg); err == nil {
+		if !info.IsDir() {
+			return []string{arg}, nil
+		}
+		var files []string
+		err = filepath.WalkDir(arg, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && sarifExtension(p) != "" {
+				files = append(files, p)
+			}
+			return nil
+		})
 		if err != nil {
-			fmt.Println(err)
-			return
+			return nil, err
 		}
-		// A preliminary check to see if the file is a valid SARIF file.
-		if _, err := sarif.FromBytes(sarifBytes); err != nil {
-			fmt.Println(err)
-			return
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no SARIF files found in %v", arg)
 		}
+		sort.Strings(files)
+		return files, nil
+	}
 
-		// gzip compress the file
-		var gBuff bytes.Buffer
-		gWriter := gzip.NewWriter(&gBuff)
-		defer gWriter.Close()
-		if _, err = gWriter.Write(sarifBytes); err != nil {
-			fmt.Println(err)
-			return
+	files, err := filepath.Glob(arg)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no SARIF files match %v", arg)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// sarifExtension returns the SARIF extension of a file name, or "" if it isn't a SARIF file.
+func sarifExtension(name string) string {
+	for _, ext := range sarifExtensions {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			return name[len(name)-len(ext):]
 		}
-		gWriter.Close()
+	}
+	return ""
+}
 
-		// Base64 encode the compressed file
-		var base64Buffer bytes.Buffer
-		b64Encoder := base64.NewEncoder(base64.RawStdEncoding, &base64Buffer)
-		defer b64Encoder.Close()
-		if _, err := b64Encoder.Write(gBuff.Bytes()); err != nil {
-			fmt.Println(err)
-			return
+// fileCategory derives a category for a SARIF file from its path relative to base.
+func fileCategory(base string, file string) string {
+	rel, err := filepath.Rel(base, file)
+	if err != nil {
+		rel = file
+	}
+	rel = filepath.ToSlash(rel)
+	if ext := sarifExtension(rel); ext != "" {
+		return strings.TrimSuffix(rel, ext)
+	}
+	return strings.TrimSuffix(rel, filepath.Ext(rel))
+}
+
+// loadSarif reads a SARIF file, both as go-sarif types and as generic JSON for modification.
+func loadSarif(file string) (*sarif.Report, map[string]interface{}, error) {
+	b, err := readSarifInput(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	// A preliminary check to see if the file is a valid SARIF file.
+	report, err := sarif.FromBytes(b)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v: %w", file, err)
+	}
+	doc, err := decodeSarifJSON(b)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v: %w", file, err)
+	}
+	return report, doc, nil
+}
+
+// mergeSarif combines several SARIF logs into a single log containing all of their runs,
+// the same way the CodeQL action does when uploading a directory.
+func mergeSarif(reports []*sarif.Report, docs []map[string]interface{}) (*sarif.Report, map[string]interface{}) {
+	merged := &sarif.Report{Version: string(sarif.Version210)}
+	var runs []interface{}
+	for i, doc := range docs {
+		merged.Runs = append(merged.Runs, reports[i].Runs...)
+		if r, ok := doc["runs"].([]interface{}); ok {
+			runs = append(runs, r...)
+		}
+	}
+	doc := map[string]interface{}{"version": string(sarif.Version210), "runs": runs}
+	if schema, ok := docs[0]["$schema"]; ok {
+		doc["$schema"] = schema
+	}
+	return merged, doc
+}
+
+// limitsError is returned when a SARIF log exceeds GitHub's limits.
+type limitsError struct {
+	file       string
+	violations []limitViolation
+}
+
+func (e *limitsError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v exceeds GitHub's SARIF limits:", e.file)
+	for _, v := range e.violations {
+		fmt.Fprintf(&sb, "\n  %v", v)
+	}
+	if !truncateFlag {
+		sb.WriteString("\nUse --truncate to drop the excess before uploading.")
+	}
+	return sb.String()
+}
+
+// prepareSarif readies a SARIF log for upload and returns it both as plain JSON and gzipped.
+// It sets the category on every run if one is given, applies --truncate and checks GitHub's limits.
+func prepareSarif(name string, report *sarif.Report, doc map[string]interface{}, category string) ([]byte, []byte, error) {
+	// Set the category on every run so that this upload doesn't replace analyses from other tools or jobs.
+	if category != "" {
+		if existing := runCategories(report); len(existing) > 1 {
+			fmt.Fprintf(os.Stderr, "Warning: %v has runs with conflicting categories (%v), all runs will use category %v\n",
+				name, strings.Join(existing, ", "), categoryAutomationID(category))
+		}
+		if err := setCategory(doc, category); err != nil {
+			return nil, nil, err
 		}
-		b64Encoder.Close()
+	}
 
-		// Set the body
-		body := sarifUpload{
-			CommitSha: args[0],
-			Ref:       args[1],
-			Sarif:     base64Buffer.String(),
-			Validate:  true,
+	// Trim anything that exceeds GitHub's limits, explaining what was dropped.
+	if truncateFlag {
+		for _, d := range truncateToLimits(doc) {
+			fmt.Fprintf(os.Stderr, "Truncated %v: %v\n", name, d)
 		}
-		var requestBody bytes.Buffer
-		jsonEncoder := json.NewEncoder(&requestBody)
-		jsonEncoder.Encode(body)
+	}
 
-		// Upload the SARIF file
-		baseURL := fmt.Sprintf("repos/%v/%v/code-scanning/sarifs", repo.Owner, repo.Name)
-		u, err := url.Parse(baseURL)
+	sarifBytes, err := encodeSarifJSON(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// gzip compress the file
+	var gBuff bytes.Buffer
+	gWriter := gzip.NewWriter(&gBuff)
+	defer gWriter.Close()
+	if _, err = gWriter.Write(sarifBytes); err != nil {
+		return nil, nil, err
+	}
+	gWriter.Close()
+
+	// Check GitHub's limits before uploading, reporting every violation at once.
+	violations := append(checkLimits(doc), checkGzipSize(gBuff.Len())...)
+	if len(violations) > 0 {
+		return nil, nil, &limitsError{file: name, violations: violations}
+	}
+	return sarifBytes, gBuff.Bytes(), nil
+}
+
+// uploadSarif uploads a gzipped SARIF log for the given commit and ref.
+func uploadSarif(client *api.RESTClient, repo repository.Repository, commitSha string, ref string, checkout string, gzipped []byte) (uploadedOK, error) {
+	var uOK uploadedOK
+
+	// Base64 encode the compressed file
+	var base64Buffer bytes.Buffer
+	b64Encoder := base64.NewEncoder(base64.RawStdEncoding, &base64Buffer)
+	defer b64Encoder.Close()
+	if _, err := b64Encoder.Write(gzipped); err != nil {
+		return uOK, err
+	}
+	b64Encoder.Close()
+
+	// Set the body
+	body := sarifUpload{
+		CommitSha:   commitSha,
+		Ref:         ref,
+		Sarif:       base64Buffer.String(),
+		CheckoutURI: checkout,
+		StartedAt:   startedAtFlag,
+		ToolName:    toolNameUploadFlag,
+		Validate:    !noValidateFlag,
+	}
+	var requestBody bytes.Buffer
+	jsonEncoder := json.NewEncoder(&requestBody)
+	if err := jsonEncoder.Encode(body); err != nil {
+		return uOK, err
+	}
+
+	// Upload the SARIF file
+	baseURL := fmt.Sprintf("repos/%v/%v/code-scanning/sarifs", repo.Owner, repo.Name)
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return uOK, err
+	}
+
+	response, err := client.Request(http.MethodPost, u.String(), &requestBody)
+	if err != nil {
+		return uOK, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusAccepted {
+		return uOK, errors.New("failed to upload SARIF file")
+	}
+	b, err := io.ReadAll(response.Body)
+	if err != nil {
+		return uOK, err
+	}
+	err = json.Unmarshal(b, &uOK)
+	return uOK, err
+}
+
+// uploadSummary is the outcome of uploading one of several SARIF files.
+type uploadSummary struct {
+	File     string   `json:"file"`
+	Category string   `json:"category"`
+	ID       string   `json:"id"`
+	Status   string   `json:"status"`
+	Errors   []string `json:"errors"`
+	Failed   bool     `json:"failed"`
+}
+
+// uploadSarifFiles uploads each SARIF file as a separate analysis, several at a time.
+// Files that don't set a category of their own are given one derived from their path,
+// or prefixed with the --category flag, so that they don't replace each other.
+func uploadSarifFiles(client *api.RESTClient, repo repository.Repository, commitSha string, ref string, checkout string, base string, files []string) []uploadSummary {
+	summaries := make([]uploadSummary, len(files))
+	sem := make(chan struct{}, uploadConcurrency)
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		go func(i int, file string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			s := uploadSummary{File: file}
+			fail := func(err error) {
+				s.Status = "error"
+				s.Errors = append(s.Errors, err.Error())
+				s.Failed = true
+				summaries[i] = s
+			}
+
+			report, doc, err := loadSarif(file)
+			if err != nil {
+				fail(err)
+				return
+			}
+			var category string
+			if categoryFlag != "" {
+				category = strings.TrimSuffix(categoryFlag, "/") + "/" + fileCategory(base, file)
+			} else if len(runCategories(report)) == 0 {
+				category = fileCategory(base, file)
+			}
+			if category != "" {
+				s.Category = categoryAutomationID(category)
+			} else {
+				s.Category = strings.Join(runCategories(report), ", ")
+			}
+
+			_, gzipped, err := prepareSarif(file, report, doc, category)
+			if err != nil {
+				fail(err)
+				return
+			}
+			uOK, err := uploadSarif(client, repo, commitSha, ref, checkout, gzipped)
+			if err != nil {
+				fail(err)
+				return
+			}
+			s.ID = uOK.UploadID
+			s.Status = "uploaded"
+
+			if waitFlag {
+				status, err := waitForProcessing(client, repo, uOK.UploadID, waitTimeoutFlag)
+				if err != nil {
+					fail(err)
+					return
+				}
+				s.Status = status.ProcessingStatus
+				s.Errors = status.Errors
+				s.Failed = status.ProcessingStatus == "failed"
+			}
+			summaries[i] = s
+		}(i, file)
+	}
+	wg.Wait()
+	return summaries
+}
+
+// printUploadSummaries prints a table with the outcome of each uploaded SARIF file.
+func printUploadSummaries(summaries []uploadSummary) error {
+	red := func(s string) string {
+		return "\u001B[91m" + s + "\u001B[39m"
+	}
+
+	cyan := func(s string) string {
+		return "\u001B[96m" + s + "\u001B[39m"
+	}
+
+	terminal := term.FromEnv()
+	termWidth, _, _ := terminal.Size()
+	t := tableprinter.New(terminal.Out(), terminal.IsTerminalOutput(), termWidth)
+	t.AddHeader([]string{"File", "Category", "ID", "Status", "Errors"})
+	for _, s := range summaries {
+		state := cyan
+		if s.Failed {
+			state = red
+		}
+		t.AddField(s.File, tableprinter.WithTruncate(nil))
+		t.AddField(s.Category)
+		t.AddField(s.ID)
+		t.AddField(s.Status, tableprinter.WithColor(state))
+		t.AddField(strings.Join(s.Errors, "; "))
+		t.EndRow()
+	}
+	return t.Render()
+}
+
+// uploadCmd represents the upload command
+var uploadCmd = &cobra.Command{
+	Use:   "upload [flags] [<commit_sha>] [<ref>] <sarif_file | directory | glob>",
+	Short: "Upload a SARIF file to GitHub Code Scanning",
+	Long: `Upload a SARIF file to GitHub Code Scanning.
+
+	The commit SHA and ref are optional. When omitted, they are read from GITHUB_SHA and GITHUB_REF
+	under GitHub Actions, or from HEAD and the current branch of the local git checkout otherwise.
+
+	Use "-" to read the SARIF file from stdin. Gzipped SARIF files are decompressed automatically.
+
+	A directory (searched recursively for .sarif files) or a quoted glob uploads several files at once.
+	By default each file is uploaded as a separate analysis, with a category derived from its path.
+	Use --merge to combine the files into a single multi-run SARIF log instead.`,
+	Args: cobra.RangeArgs(1, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		sarifArg := args[len(args)-1]
+
+		if startedAtFlag != "" {
+			if _, err := time.Parse(time.RFC3339, startedAtFlag); err != nil {
+				fmt.Printf("invalid --started-at %q: must be an ISO 8601 timestamp (e.g. 2006-01-02T15:04:05Z)\n", startedAtFlag)
+				os.Exit(1)
+			}
+		}
+		var checkout string
+		if checkoutURIFlag != "" {
+			var err error
+			if checkout, err = checkoutURI(checkoutURIFlag); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		files, err := expandSarifPaths(sarifArg)
 		if err != nil {
 			fmt.Println(err)
-			return
+			os.Exit(1)
+		}
+		separate := len(files) > 1 && !mergeFlag
+		if separate && uploadOutputFlag != "" {
+			fmt.Println("--output needs a single SARIF file, or --merge to combine several.")
+			os.Exit(1)
+		}
+		fields := jsonFields(uploadResult{})
+		if separate {
+			fields = jsonFields(uploadSummary{})
+		}
+		if err := checkJSONFields(fields); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
 
-		var opts api.ClientOptions
-		// If GHES, set the host
-		if repo.Host != "" {
-			opts.Host = repo.Host
+		// Read the SARIF file(s), merging them into one log if there are several.
+		var gzipped []byte
+		if !separate {
+			var reports []*sarif.Report
+			var docs []map[string]interface{}
+			for _, file := range files {
+				report, doc, err := loadSarif(file)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				reports = append(reports, report)
+				docs = append(docs, doc)
+			}
+			name := files[0]
+			report, doc := reports[0], docs[0]
+			if len(files) > 1 {
+				name = sarifArg
+				report, doc = mergeSarif(reports, docs)
+				fmt.Fprintf(os.Stderr, "Merged %v SARIF files into a single log with %v runs.\n", len(files), len(report.Runs))
+			}
+
+			var sarifBytes []byte
+			sarifBytes, gzipped, err = prepareSarif(name, report, doc, categoryFlag)
+			if err != nil {
+				// Exit non-zero like the multi-file path, so CI notices a SARIF file that can't be uploaded.
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			// Write the SARIF that would have been uploaded instead of uploading it.
+			if uploadOutputFlag != "" {
+				if err := writeOutput(uploadOutputFlag, sarifBytes); err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				return
+			}
 		}
-		opts.Headers = map[string]string{"Accept": "application/json"}
 
-		client, err := api.NewRESTClient(opts)
+		// Setup Repository
+		repo, err := GetRepository()
 		if err != nil {
 			fmt.Println(err)
-			return
+			os.Exit(1)
 		}
 
-		response, err := client.Request(http.MethodPost, u.String(), &requestBody)
+		commitSha, ref, _, err := resolveUploadArgs(args)
 		if err != nil {
 			fmt.Println(err)
-			return
-		}
-		if response.StatusCode != http.StatusAccepted {
-			fmt.Println("Failed to upload SARIF file.")
-			return
+			os.Exit(1)
 		}
-		b, err := io.ReadAll(response.Body)
-		var uOK uploadedOK
+
+		client, err := newRESTClient(repo, map[string]string{"Accept": "application/json"})
 		if err != nil {
 			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		// Upload several files as separate analyses and summarize the outcome.
+		if separate {
+			base := sarifArg
+			if info, err := os.Stat(sarifArg); err != nil || !info.IsDir() {
+				base = "."
+			}
+			summaries := uploadSarifFiles(client, repo, commitSha, ref, checkout, base, files)
+			if jsonOutput() {
+				b, err := json.Marshal(summaries)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				err = printJSON(b)
+				if err != nil {
+					fmt.Println(err)
+				}
+			} else if err := printUploadSummaries(summaries); err != nil {
+				fmt.Println(err)
+			}
+			for _, s := range summaries {
+				if s.Failed {
+					os.Exit(1)
+				}
+			}
 			return
 		}
-		err = json.Unmarshal(b, &uOK)
+
+		uOK, err := uploadSarif(client, repo, commitSha, ref, checkout, gzipped)
 		if err != nil {
 			fmt.Println(err)
-			return
+			os.Exit(1)
+		}
+		result := uploadResult{uploadedOK: uOK}
+		if !jsonOutput() {
+			fmt.Printf("SARIF file uploaded successfully.\n\nID: %v\nURL: %v\n", uOK.UploadID, uOK.UploadURL)
+		}
+
+		if waitFlag {
+			if !jsonOutput() {
+				fmt.Printf("\nWaiting for SARIF processing to finish...\n")
+			}
+			status, err := waitForProcessing(client, repo, uOK.UploadID, waitTimeoutFlag)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			result.sarifStatus = &status
+			if status.AnalysesURL != "" {
+				if result.Analyses, err = getUploadAnalyses(client, status.AnalysesURL); err != nil {
+					fmt.Println(err)
+				}
+			}
+		}
+
+		if jsonOutput() {
+			b, err := json.Marshal(result)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			if err = printJSON(b); err != nil {
+				fmt.Println(err)
+			}
+		} else if result.sarifStatus != nil {
+			fmt.Printf("Processing status: %v\n", result.ProcessingStatus)
+			if len(result.Analyses) > 0 {
+				fmt.Println("\nAnalyses:")
+				for _, a := range result.Analyses {
+					fmt.Printf("  %v\n", a.URL)
+				}
+			}
+			if len(result.Errors) > 0 {
+				fmt.Println("\nErrors:")
+				for _, e := range result.Errors {
+					fmt.Printf("  %v\n", e)
+				}
+			}
+		}
+		if result.sarifStatus != nil && result.ProcessingStatus == "failed" {
+			os.Exit(1)
 		}
-		fmt.Printf("SARIF file uploaded successfully.\n\nID: %v\nURL: %v\n", uOK.UploadID, uOK.UploadURL)
 	},
 }
 
+var waitFlag bool
+var waitTimeoutFlag time.Duration
+var checkoutURIFlag string
+var startedAtFlag string
+var toolNameUploadFlag string
+var noValidateFlag bool
+var categoryFlag string
+var truncateFlag bool
+var mergeFlag bool
+var uploadOutputFlag string
+
 func init() {
 	rootCmd.AddCommand(uploadCmd)
+	setJSONFields(uploadCmd, uploadResult{})
+
+	uploadCmd.Flags().BoolVarP(&waitFlag, "wait", "w", false, "Wait for GitHub to finish processing the SARIF file and report the result")
+	uploadCmd.Flags().DurationVar(&waitTimeoutFlag, "wait-timeout", 5*time.Minute, "Maximum time to wait for processing when using --wait")
+	uploadCmd.Flags().StringVar(&checkoutURIFlag, "checkout-uri", "", "The base directory or file:// URI that paths in the SARIF file are relative to")
+	uploadCmd.Flags().StringVar(&startedAtFlag, "started-at", "", "The time the analysis run began (ISO 8601 format, e.g. 2006-01-02T15:04:05Z)")
+	uploadCmd.Flags().StringVarP(&toolNameUploadFlag, "tool", "t", "", "The name of the tool used to generate the SARIF data")
+	uploadCmd.Flags().StringVarP(&categoryFlag, "category", "c", "", "Set the category (runAutomationDetails.id) of every run, replacing any existing category")
+	uploadCmd.Flags().BoolVar(&truncateFlag, "truncate", false, "Drop runs, rules, results, locations and tags that exceed GitHub's limits instead of failing")
+	uploadCmd.Flags().BoolVar(&mergeFlag, "merge", false, "Merge multiple SARIF files into a single multi-run log instead of uploading them separately")
+	uploadCmd.Flags().StringVarP(&uploadOutputFlag, "output", "o", "", "Write the processed SARIF to a file (\"-\" for stdout) instead of uploading it")
+	uploadCmd.Flags().BoolVar(&noValidateFlag, "no-validate", false, "Skip validation of the SARIF file against the code scanning specification")
 
 	// Here you will define your flags and configuration settings.
 
@@ -22,16 +22,15 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 
-	"github.com/cli/go-gh/v2/pkg/api"
-	"github.com/cli/go-gh/v2/pkg/jsonpretty"
 	"github.com/cli/go-gh/v2/pkg/markdown"
 	"github.com/cli/go-gh/v2/pkg/tableprinter"
 	"github.com/cli/go-gh/v2/pkg/term"
@@ -44,8 +43,10 @@ var viewCmd = &cobra.Command{
 	Use:   "view [<analysis-id> | <sarif-file>] [--sarif | --csv | --json]",
 	Short: "View GitHub Code Scanning analysis or SARIF results",
 	Long: `View results given the GitHub analysis ID or SARIF file.
+	Use "-" to read the SARIF file from stdin. Gzipped SARIF files are decompressed automatically.
 	
-	Use --sarif to get a subset of the analysis SARIF from GitHub.`,
+	Use --sarif to get a subset of the analysis SARIF from GitHub.
+	Use --jq or --template to filter the SARIF, or the analysis metadata with --json.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Setup Repository
@@ -55,15 +56,67 @@ var viewCmd = &cobra.Command{
 			return
 		}
 
+		// With --flows, --json selects fields of the thread flows instead of the analysis.
+		available := jsonFields(Analysis{})
+		if flowsFlag {
+			available = jsonFields(threadFlow{})
+		}
+		if err := checkJSONFields(available); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		format := formatFlag
+		if csvFlag {
+			format = "csv"
+		}
+		switch format {
+		case "table", "csv", "tsv":
+		default:
+			fmt.Printf("invalid --format %q: must be table, csv or tsv\n", format)
+			return
+		}
+		if viewOutputFlag != "" && format == "table" {
+			fmt.Println("--output needs --format csv or tsv.")
+			return
+		}
+
+		if cmd.Flags().Changed("result") && cmd.Flags().Changed("alert") {
+			fmt.Println("Use either --result or --alert, not both.")
+			return
+		}
+		selected := cmd.Flags().Changed("result") || cmd.Flags().Changed("alert")
+		if selected && format != "table" {
+			fmt.Println("--result and --alert can't be used with --format csv or tsv.")
+			return
+		}
+		if flowsFlag && !selected {
+			fmt.Println("--flows needs --result or --alert to select a result.")
+			return
+		}
+		if cmd.Flags().Changed("context") && (format != "table" || contextFlag < 0) {
+			fmt.Println("--context needs a number of lines of at least 0 and can't be used with --format csv or tsv.")
+			return
+		}
+
+		filter, err := newResultFilter()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
 		terminal := term.FromEnv()
-		isTerminal := terminal.IsTerminalOutput()
 		var b []byte // SARIF bytes
 
 		// Check if the argument is a file path or an analysis ID
 		// If file path, read the file and parse it as SARIF
 		// If analysis ID, make a request to the API to get the SARIF
-		if f, _ := os.Stat(args[0]); f != nil {
-			b, err = os.ReadFile(args[0])
+		if f, _ := os.Stat(args[0]); f != nil || args[0] == stdinPath {
+			if len(jsonFlag) > 0 && !flowsFlag {
+				fmt.Println("--json selects fields of an analysis, use --sarif or --jq for SARIF files.")
+				return
+			}
+			b, err = readSarifInput(args[0])
 			if err != nil {
 				fmt.Println(err)
 				return
@@ -76,19 +129,13 @@ var viewCmd = &cobra.Command{
 				return
 			}
 
-			var opts api.ClientOptions
+			var headers map[string]string
 			// Always get the SARIF directly unless the JSON meta is requested instead
-			if !jsonFlag {
-				opts = api.ClientOptions{
-					Headers: map[string]string{"Accept": "application/sarif+json"},
-				}
-			}
-			// If GHES, set the host
-			if repo.Host != "" {
-				opts.Host = repo.Host
+			if len(jsonFlag) == 0 || flowsFlag {
+				headers = map[string]string{"Accept": "application/sarif+json"}
 			}
 
-			client, err := api.NewRESTClient(opts)
+			client, err := newRESTClient(repo, headers)
 			if err != nil {
 				fmt.Println(err)
 				return
@@ -116,102 +163,107 @@ var viewCmd = &cobra.Command{
 		// Write pretty JSON or SARIF to stdout.
 		// JSON is the analysis metadata in JSON, not the actual SARIF.
 		// SARIF is the complete SARIF file.
-		if jsonFlag || sarifFlag {
-			s := string(b)
-			if isTerminal {
-				if err = jsonpretty.Format(os.Stdout, bytes.NewBufferString(s), "\t", true); err != nil {
-					fmt.Println(err)
-				}
-			} else {
-				if err = jsonpretty.Format(os.Stdout, bytes.NewBufferString(s), "\t", false); err != nil {
-					fmt.Println(err)
-				}
+		// --jq and --template apply to the metadata if --json is set, and to the SARIF otherwise.
+		if (jsonOutput() || sarifFlag) && !flowsFlag {
+			if err = printJSON(b); err != nil {
+				fmt.Println(err)
 			}
 			return
 		}
 
-		// Print results to stdout in a table if no other options.
-		termWidth, _, _ := terminal.Size()
-		t := tableprinter.New(terminal.Out(), terminal.IsTerminalOutput(), termWidth)
-
-		t.AddHeader([]string{"Rule", "Description", "Alert Number", "Severity"})
 		if len(r.Runs) <= 0 {
 			fmt.Println("No results found.")
 			return
 		}
+		rows := collectResults(r)
+		// If no results in any runs within the analysis...
+		if len(rows) == 0 {
+			fmt.Println("No results found in analysis.")
+			return
+		}
 
-		// Print results in a table
-		empty := true
-		for _, run := range r.Runs {
-			// This means this run has no results
-			if len(run.Results) <= 0 {
-				continue
-			}
-			if csvFlag {
-				fmt.Printf("%v,%v,%v,%v\n", "Rule ID", "Description", "Alert Number", "Severity")
+		// Print everything about a single result, or its code flows.
+		if selected {
+			row, err := selectResult(rows, cmd.Flags().Changed("alert"), resultFlag, alertFlag)
+			if err != nil {
+				fmt.Println(err)
+				return
 			}
-			empty = false
-			for _, result := range run.Results {
-				if result.Message.Text == nil {
-					result.Message.Text = new(string)
-					*result.Message.Text = "-"
-				}
-				if result.RuleID == nil {
-					result.RuleID = new(string)
-					*result.RuleID = "-"
-				}
-				if result.Level == nil {
-					result.Level = new(string)
-					*result.Level = "-"
-				}
-				if _, ok := result.Properties["github/alertNumber"]; !ok {
-					if result.Properties == nil {
-						result.Properties = sarif.Properties{}
-					}
-					result.Properties["github/alertNumber"] = "-"
-				}
-				// Print to CSV if flag is set
-				if csvFlag {
-					m := *result.Message.Text
-					// Attempts at making the CSV output valid. Might need to revist formally.
-					m = strings.ReplaceAll(m, "\n", " ")
-					m = strings.ReplaceAll(m, "\r", " ")
-					m = strings.ReplaceAll(m, `"`, `""`)
-					fmt.Printf("%v,%v,%v,%v\n", *result.RuleID, `"`+m+`"`, result.Properties["github/alertNumber"], *result.Level)
-					continue
+			if !flowsFlag {
+				if err := printResultDetail(terminal, row, contextFlag); err != nil {
+					fmt.Println(err)
 				}
-
-				m := *result.Message.Text
-				if strings.Contains(m, "\n") {
-					m = strings.Split(m, "\n")[0]
-					m += " ..."
+				return
+			}
+			flows := row.threadFlows()
+			if jsonOutput() {
+				if flows == nil {
+					flows = []threadFlow{}
 				}
-				// Render markdown in the description
-				m, err := markdown.Render(m, markdown.WithTheme("dark"))
-				if err != nil {
+				j, _ := json.Marshal(flows)
+				if err := printJSON(j); err != nil {
 					fmt.Println(err)
-					return
 				}
-				m = strings.ReplaceAll(m, "\n", "")
-				m = strings.ReplaceAll(m, "\r", "")
-				m = strings.Join(strings.Fields(m), " ")
-
-				t.AddField(*result.RuleID)
-				t.AddField(m)
-				t.AddField(fmt.Sprintf("%v", result.Properties["github/alertNumber"]))
-				t.AddField(*result.Level)
-				t.EndRow()
+				return
 			}
+			if err := printThreadFlows(terminal.Out(), row, flows, contextFlag, terminal.IsColorEnabled()); err != nil {
+				fmt.Println(err)
+			}
+			return
 		}
-		// If no results in any runs within the analysis...
-		if empty {
-			fmt.Println("No results found in analysis.")
+
+		rows = filter.filter(rows)
+		if len(rows) == 0 {
+			fmt.Println("No results match the filters.")
 			return
 		}
-		// Don't try to render table if output is CSV.
-		if csvFlag {
+
+		// Print results as CSV or TSV, to a file if requested.
+		if format == "csv" || format == "tsv" {
+			if err := writeResults(rows, format); err != nil {
+				fmt.Println(err)
+			}
+			return
+		}
+
+		// Print each result with the source around it.
+		if cmd.Flags().Changed("context") {
+			if err := printResultSnippets(terminal, rows, contextFlag); err != nil {
+				fmt.Println(err)
+			}
 			return
 		}
+
+		// Print results to stdout in a table if no other options.
+		termWidth, _, _ := terminal.Size()
+		t := tableprinter.New(terminal.Out(), terminal.IsTerminalOutput(), termWidth)
+
+		t.AddHeader([]string{"#", "Rule", "Location", "Description", "Alert Number", "Level", "Severity"})
+		for _, row := range rows {
+			m := row.fullMessage()
+			if strings.Contains(m, "\n") {
+				m = strings.Split(m, "\n")[0]
+				m += " ..."
+			}
+			// Render markdown in the description
+			m, err := markdown.Render(m, markdown.WithTheme("dark"))
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			m = strings.ReplaceAll(m, "\n", "")
+			m = strings.ReplaceAll(m, "\r", "")
+			m = strings.Join(strings.Fields(m), " ")
+
+			t.AddField(strconv.Itoa(row.index))
+			t.AddField(orDash(row.ruleID()))
+			t.AddField(orDash(row.locationColumn()))
+			t.AddField(orDash(m))
+			t.AddField(orDash(row.alertNumber()))
+			t.AddField(orDash(row.effectiveLevel()))
+			t.AddField(orDash(row.severity()))
+			t.EndRow()
+		}
 		if err := t.Render(); err != nil {
 			fmt.Println(err)
 			return
@@ -220,12 +272,106 @@ var viewCmd = &cobra.Command{
 	},
 }
 
+// writeResults writes results as CSV or TSV to stdout, or to the file given with --output.
+func writeResults(rows []resultRow, format string) error {
+	names := columnsFlag
+	if len(names) == 0 {
+		names = defaultResultColumns
+	}
+	columns, err := selectResultColumns(names)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if viewOutputFlag != "" && viewOutputFlag != stdinPath {
+		f, err := os.Create(viewOutputFlag)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	comma := ','
+	if format == "tsv" {
+		comma = '\t'
+	}
+	return writeResultsCSV(w, rows, columns, comma)
+}
+
+// printResultSnippets prints each result followed by the lines around its primary location.
+func printResultSnippets(terminal term.Term, rows []resultRow, context int) error {
+	w := terminal.Out()
+	color := terminal.IsColorEnabled()
+	for i, row := range rows {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		heading := fmt.Sprintf("%v  %v  %v", orDash(row.ruleID()), row.severity(), orDash(row.location()))
+		if color {
+			heading = "\033[1m" + heading + "\033[0m"
+		}
+		fmt.Fprintln(w, heading)
+		fmt.Fprintln(w, row.fullMessage())
+
+		s, err := loadSnippet(row.run, row.primaryLocation(), context)
+		if err != nil {
+			fmt.Fprintf(w, "(no source: %v)\n", err)
+			continue
+		}
+		if err := printSnippet(w, s, color); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orDash returns s, or "-" if it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 var sarifFlag bool
 var csvFlag bool
+var formatFlag string
+var columnsFlag []string
+var viewOutputFlag string
+var allLocationsFlag bool
+var contextFlag int
+var flowsFlag bool
+var resultFlag int
+var alertFlag int
+var levelFilterFlag []string
+var ruleFilterFlag string
+var pathFilterFlag string
+var toolFilterFlag string
+var minSecuritySeverityFlag float64
+var tagFilterFlag []string
 
 func init() {
 	rootCmd.AddCommand(viewCmd)
+	setJSONFields(viewCmd, Analysis{})
 
 	viewCmd.Flags().BoolVarP(&sarifFlag, "sarif", "S", false, "Print raw SARIF to stdout")
-	viewCmd.Flags().BoolVarP(&csvFlag, "csv", "c", false, "Print results in CSV format")
+	viewCmd.Flags().BoolVarP(&csvFlag, "csv", "c", false, "Print results in CSV format (alias for --format csv)")
+	viewCmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format: table, csv or tsv")
+	viewCmd.Flags().StringSliceVar(&columnsFlag, "columns", nil, fmt.Sprintf("Columns of CSV and TSV output (default %v; available: %v)", strings.Join(defaultResultColumns, ","), strings.Join(resultColumnNames(), ", ")))
+	viewCmd.Flags().IntVar(&resultFlag, "result", 0, "Show everything about the result with this index in the # column")
+	viewCmd.Flags().IntVar(&alertFlag, "alert", 0, "Show everything about the result of this alert number")
+	viewCmd.Flags().BoolVar(&flowsFlag, "flows", false, "Show the code flows of the result picked by --result or --alert")
+	viewCmd.Flags().IntVar(&contextFlag, "context", 0, "Print each result with `N` lines of source around it")
+	viewCmd.Flags().BoolVar(&allLocationsFlag, "all-locations", false, "Show every location of a result, not just the primary one")
+	viewCmd.Flags().StringVarP(&viewOutputFlag, "output", "o", "", "Write CSV or TSV output to a file")
+
+	// Result filters
+	viewCmd.Flags().StringSliceVar(&levelFilterFlag, "level", nil, "Only show results with these levels: none, note, warning, error")
+	viewCmd.Flags().StringVar(&ruleFilterFlag, "rule", "", "Only show results of rules whose ID matches a glob (* matches any characters, including /)")
+	viewCmd.Flags().StringVar(&pathFilterFlag, "path", "", "Only show results with a location matching a glob (** matches any directories)")
+	viewCmd.Flags().StringVar(&toolFilterFlag, "tool", "", "Only show results of the tool with this name")
+	viewCmd.Flags().Float64Var(&minSecuritySeverityFlag, "min-security-severity", 0, "Only show results with at least this security-severity score")
+	viewCmd.Flags().StringSliceVar(&tagFilterFlag, "tag", nil, "Only show results of rules with any of these tags")
 }